@@ -0,0 +1,120 @@
+package eventschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CheckCompatible reports what a consumer expecting documents matching consumer could fail to read
+// in documents matching producer. It is conservative: a constraint of the consumer the producer
+// doesn't promise is a problem even when the producer happens to respect it.
+func CheckCompatible(producer *Schema, consumer *Schema) []string {
+	var problems []string
+	checkCompatible(producer, consumer, "$", &problems)
+	return problems
+}
+
+func checkCompatible(producer *Schema, consumer *Schema, path string, problems *[]string) {
+	producer, err := producer.resolve()
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: producer %v", path, err))
+		return
+	}
+	consumer, err = consumer.resolve()
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: consumer %v", path, err))
+		return
+	}
+	add := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(consumer.Type) > 0 {
+		if len(producer.Type) == 0 {
+			add("may be of any type, consumer expects %s", strings.Join(consumer.Type, " or "))
+		}
+		for _, typ := range producer.Type {
+			if !consumer.Type.allows(typ) {
+				add("may be %s, consumer expects %s", typ, strings.Join(consumer.Type, " or "))
+			}
+		}
+	}
+	if len(consumer.Enum) > 0 {
+		if len(producer.Enum) == 0 {
+			add("may be any value, consumer expects one of its enum")
+		}
+		for _, value := range producer.Enum {
+			if !containsJSON(consumer.Enum, value) {
+				add("may be %s, consumer doesn't know it", value)
+			}
+		}
+	}
+	if consumer.MinLength != nil && (producer.MinLength == nil || *producer.MinLength < *consumer.MinLength) {
+		add("may be shorter than %d", *consumer.MinLength)
+	}
+	if consumer.Minimum != nil && (producer.Minimum == nil || *producer.Minimum < *consumer.Minimum) {
+		add("may be less than %v", *consumer.Minimum)
+	}
+	if consumer.Pattern != "" && producer.Pattern != consumer.Pattern {
+		add("may not match %s", consumer.Pattern)
+	}
+	if consumer.Format != "" && producer.Format != consumer.Format {
+		add("may not be a %s", consumer.Format)
+	}
+
+	for _, name := range consumer.Required {
+		if !slices.Contains(producer.Required, name) {
+			add("%s is required by the consumer but may be missing", name)
+		}
+	}
+	for _, name := range sortedKeys(consumer.Properties) {
+		property, ok := producer.Properties[name]
+		if ok {
+			checkCompatible(property, consumer.Properties[name], path+"."+name, problems)
+			continue
+		}
+		// a closed producer never sends it, which only matters when it is required
+		if !closed(producer) {
+			add("%s is read by the consumer but not described by the producer", name)
+		}
+	}
+	if closed(consumer) {
+		if !closed(producer) {
+			add("may have properties the consumer doesn't allow")
+		}
+		for _, name := range sortedKeys(producer.Properties) {
+			if _, ok := consumer.Properties[name]; !ok {
+				add("%s is not allowed by the consumer", name)
+			}
+		}
+	}
+
+	if consumer.Items != nil {
+		if producer.Items == nil {
+			add("items are not described by the producer")
+		} else {
+			checkCompatible(producer.Items, consumer.Items, path+"[]", problems)
+		}
+	}
+}
+
+func closed(s *Schema) bool {
+	return s.AdditionalProperties != nil && !*s.AdditionalProperties
+}
+
+func containsJSON(values []json.RawMessage, value json.RawMessage) bool {
+	var want bytes.Buffer
+	if json.Compact(&want, value) != nil {
+		return false
+	}
+	for _, candidate := range values {
+		var got bytes.Buffer
+		if json.Compact(&got, candidate) == nil && bytes.Equal(got.Bytes(), want.Bytes()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,3 @@
+module eventschema
+
+go 1.24.5
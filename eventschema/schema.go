@@ -0,0 +1,257 @@
+// Package eventschema describes the payloads of the events the services exchange: it validates
+// events against their JSON Schema, checks that what a producer publishes is readable by the
+// contract of a consumer, and that a consumer's contract is the struct it decodes events into.
+package eventschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrSchemaViolation is returned when an event doesn't match its schema.
+var ErrSchemaViolation = errors.New("event does not match its schema")
+
+// Schema is a JSON Schema restricted to the keywords the event schemas need: type, properties,
+// required, additionalProperties, items, enum, minimum, minLength, pattern, format date-time and
+// references to the $defs of the same document. Any other keyword is refused when the schema is
+// parsed so that it is never silently ignored.
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	Type                 typeList           `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []json.RawMessage  `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+
+	root    *Schema
+	pattern *regexp.Regexp
+}
+
+// typeList is the type keyword, a single type or a list of them.
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = typeList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+func (t typeList) allows(typ string) bool {
+	for _, allowed := range t {
+		if allowed == typ || (allowed == "number" && typ == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse reads a schema document.
+func Parse(data []byte) (*Schema, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var schema Schema
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, err
+	}
+	if err := schema.prepare(&schema, "$"); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (s *Schema) prepare(root *Schema, path string) error {
+	s.root = root
+	for _, typ := range s.Type {
+		switch typ {
+		case "null", "boolean", "integer", "number", "string", "array", "object":
+		default:
+			return fmt.Errorf("%s: unknown type %q", path, typ)
+		}
+	}
+	if s.Format != "" && s.Format != "date-time" {
+		return fmt.Errorf("%s: unsupported format %q", path, s.Format)
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		s.pattern = pattern
+	}
+	if s.Ref != "" {
+		if _, err := s.resolve(); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	for name, def := range s.Defs {
+		if err := def.prepare(root, path+".$defs."+name); err != nil {
+			return err
+		}
+	}
+	for name, property := range s.Properties {
+		if err := property.prepare(root, path+"."+name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.prepare(root, path+"[]")
+	}
+	return nil
+}
+
+// resolve follows $ref, only local references to $defs are supported.
+func (s *Schema) resolve() (*Schema, error) {
+	if s.Ref == "" {
+		return s, nil
+	}
+	name, ok := strings.CutPrefix(s.Ref, "#/$defs/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q", s.Ref)
+	}
+	def, ok := s.root.Defs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown $ref %q", s.Ref)
+	}
+	return def.resolve()
+}
+
+// Validate checks a JSON document against the schema. The returned error wraps
+// ErrSchemaViolation and lists every mismatch.
+func (s *Schema) Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%w: %v", ErrSchemaViolation, err)
+	}
+	var problems []string
+	s.validate(value, "$", &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrSchemaViolation, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (s *Schema) validate(value interface{}, path string, problems *[]string) {
+	s, err := s.resolve()
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+		return
+	}
+
+	typ := typeOf(value)
+	if len(s.Type) > 0 && !s.Type.allows(typ) {
+		*problems = append(*problems, fmt.Sprintf("%s: is %s, want %s", path, typ, strings.Join(s.Type, " or ")))
+		return
+	}
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		*problems = append(*problems, fmt.Sprintf("%s: %v is not one of the allowed values", path, value))
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.MinLength != nil && utf8.RuneCountInString(v) < *s.MinLength {
+			*problems = append(*problems, fmt.Sprintf("%s: shorter than %d", path, *s.MinLength))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			*problems = append(*problems, fmt.Sprintf("%s: %q does not match %s", path, v, s.Pattern))
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				*problems = append(*problems, fmt.Sprintf("%s: %q is not a date-time", path, v))
+			}
+		}
+	case json.Number:
+		if s.Minimum != nil {
+			number, err := strconv.ParseFloat(v.String(), 64)
+			if err != nil || number < *s.Minimum {
+				*problems = append(*problems, fmt.Sprintf("%s: %s is less than %v", path, v, *s.Minimum))
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: missing %s", path, name))
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*problems = append(*problems, fmt.Sprintf("%s: unexpected property %s", path, name))
+				}
+				continue
+			}
+			property.validate(v[name], path+"."+name, problems)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	}
+}
+
+func (s *Schema) inEnum(value interface{}) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return containsJSON(s.Enum, encoded)
+}
+
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
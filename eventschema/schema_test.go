@@ -0,0 +1,134 @@
+package eventschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const orderSchema = `{
+  "type": "object",
+  "required": ["order_id", "status", "total"],
+  "additionalProperties": false,
+  "properties": {
+    "order_id": {"type": "integer", "minimum": 1},
+    "status": {"type": "string", "enum": ["created", "cancelled"]},
+    "total": {"$ref": "#/$defs/money"},
+    "note": {"type": "string"},
+    "items": {"type": "array", "items": {"type": "integer"}}
+  },
+  "$defs": {
+    "money": {
+      "type": "object",
+      "required": ["amount", "currency"],
+      "properties": {
+        "amount": {"type": "integer"},
+        "currency": {"type": "string", "pattern": "^[A-Z]{3}$"}
+      }
+    }
+  }
+}`
+
+func mustParse(t *testing.T, data string) *Schema {
+	t.Helper()
+	schema, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestValidate(t *testing.T) {
+	schema := mustParse(t, orderSchema)
+	tests := []struct {
+		name    string
+		payload string
+		problem string
+	}{
+		{"valid", `{"order_id": 1, "status": "created", "total": {"amount": 100, "currency": "USD"}, "items": [1, 2]}`, ""},
+		{"missing required", `{"order_id": 1, "total": {"amount": 100, "currency": "USD"}}`, "$: missing status"},
+		{"wrong type", `{"order_id": "1", "status": "created", "total": {"amount": 100, "currency": "USD"}}`, "$.order_id: is string"},
+		{"fraction for integer", `{"order_id": 1.5, "status": "created", "total": {"amount": 100, "currency": "USD"}}`, "$.order_id: is number"},
+		{"below minimum", `{"order_id": 0, "status": "created", "total": {"amount": 100, "currency": "USD"}}`, "$.order_id: 0 is less than 1"},
+		{"outside enum", `{"order_id": 1, "status": "lost", "total": {"amount": 100, "currency": "USD"}}`, "$.status: lost is not one of"},
+		{"pattern through ref", `{"order_id": 1, "status": "created", "total": {"amount": 100, "currency": "usd"}}`, "$.total.currency"},
+		{"unexpected property", `{"order_id": 1, "status": "created", "total": {"amount": 100, "currency": "USD"}, "extra": true}`, "unexpected property extra"},
+		{"array item", `{"order_id": 1, "status": "created", "total": {"amount": 100, "currency": "USD"}, "items": [1, "2"]}`, "$.items[1]: is string"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := schema.Validate([]byte(test.payload))
+			if test.problem == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrSchemaViolation) || !strings.Contains(err.Error(), test.problem) {
+				t.Fatalf("got %v, want a schema violation about %q", err, test.problem)
+			}
+		})
+	}
+}
+
+func TestParseRefusesUnsupportedKeywords(t *testing.T) {
+	for _, data := range []string{
+		`{"type": "object", "oneOf": [{"type": "string"}]}`,
+		`{"type": "decimal"}`,
+		`{"type": "string", "format": "email"}`,
+		`{"$ref": "#/$defs/missing"}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%s) succeeded", data)
+		}
+	}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	consumer := `{
+	  "type": "object",
+	  "required": ["order_id", "status"],
+	  "properties": {
+	    "order_id": {"type": "integer"},
+	    "status": {"type": "string", "enum": ["created", "cancelled"]},
+	    "total": {"type": "object", "properties": {"amount": {"type": "integer"}}}
+	  }
+	}`
+	tests := []struct {
+		name     string
+		producer string
+		problem  string
+	}{
+		{"identical", orderSchema, ""},
+		{"new optional field", strings.Replace(orderSchema, `"note": {"type": "string"},`, `"note": {"type": "string"}, "gift": {"type": "boolean"},`, 1), ""},
+		{"required field made optional", strings.Replace(orderSchema, `"required": ["order_id", "status", "total"]`, `"required": ["order_id", "total"]`, 1), "status is required by the consumer but may be missing"},
+		{"field removed", strings.NewReplacer(`"order_id": {"type": "integer", "minimum": 1},`, ``, `["order_id", "status", "total"]`, `["status", "total"]`).Replace(orderSchema), "order_id is required by the consumer"},
+		{"type changed", strings.Replace(orderSchema, `"order_id": {"type": "integer", "minimum": 1}`, `"order_id": {"type": "string"}`, 1), "$.order_id: may be string"},
+		{"enum value added", strings.Replace(orderSchema, `["created", "cancelled"]`, `["created", "cancelled", "lost"]`, 1), `may be "lost"`},
+		{"nested type changed", strings.Replace(orderSchema, `"amount": {"type": "integer"}`, `"amount": {"type": "number"}`, 1), "$.total.amount: may be number"},
+		{"read field left open", strings.Replace(orderSchema, `"additionalProperties": false,`, ``, 1), ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems := CheckCompatible(mustParse(t, test.producer), mustParse(t, consumer))
+			if test.problem == "" {
+				if len(problems) > 0 {
+					t.Fatalf("unexpected problems: %v", problems)
+				}
+				return
+			}
+			if !strings.Contains(strings.Join(problems, "\n"), test.problem) {
+				t.Fatalf("got %v, want a problem about %q", problems, test.problem)
+			}
+		})
+	}
+}
+
+func TestCheckCompatibleUndescribedField(t *testing.T) {
+	producer := mustParse(t, `{"type": "object", "properties": {"order_id": {"type": "integer"}}}`)
+	consumer := mustParse(t, `{"type": "object", "properties": {"note": {"type": "string"}}}`)
+	problems := CheckCompatible(producer, consumer)
+	if len(problems) != 1 || !strings.Contains(problems[0], "note is read by the consumer but not described") {
+		t.Fatalf("got %v", problems)
+	}
+}
@@ -0,0 +1,149 @@
+package eventschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// CheckStruct reports where the contract of a consumer and the struct it decodes events into
+// disagree: a property of the contract the struct doesn't decode or decodes into a type that can't
+// hold it, and a field of the struct the contract doesn't list. Keeping both in step is what lets
+// the producer rely on the contract.
+func CheckStruct(contract *Schema, v interface{}) []string {
+	var problems []string
+	checkStruct(contract, reflect.TypeOf(v), "$", &problems)
+	return problems
+}
+
+func checkStruct(s *Schema, t reflect.Type, path string, problems *[]string) {
+	s, err := s.resolve()
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+		return
+	}
+	add := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+	// what a custom unmarshaler accepts can't be told from its type
+	if t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+	typ := jsonType(t)
+	if typ == "" {
+		return
+	}
+	if len(s.Type) == 0 {
+		add("decoded into %s, the contract doesn't say its type", t)
+		return
+	}
+	for _, contractType := range s.Type {
+		if contractType == "null" && (nullable || t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+			continue
+		}
+		if !(typeList{typ}).allows(contractType) {
+			add("may be %s, decoded into %s", contractType, t)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := jsonFields(t)
+		for _, name := range sortedKeys(s.Properties) {
+			field, ok := fields[name]
+			if !ok {
+				add("%s is in the contract but the struct doesn't decode it", name)
+				continue
+			}
+			checkStruct(s.Properties[name], field, path+"."+name, problems)
+		}
+		for _, name := range sortedKeys(fields) {
+			if _, ok := s.Properties[name]; !ok {
+				add("%s is decoded by the struct but not in the contract", name)
+			}
+		}
+		for _, name := range s.Required {
+			if _, ok := s.Properties[name]; !ok {
+				add("%s is required but not in the properties of the contract", name)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if s.Items == nil {
+			add("items are not described by the contract")
+			return
+		}
+		checkStruct(s.Items, t.Elem(), path+"[]", problems)
+	}
+}
+
+// jsonType is the JSON Schema type encoding/json decodes into t, or "" when t takes any value.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is decoded from a base64 string
+			return "string"
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return ""
+	}
+}
+
+// jsonFields maps the names encoding/json decodes into the fields of a struct to their types,
+// following embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for embeddedName, embeddedType := range jsonFields(embedded) {
+					if _, ok := fields[embeddedName]; !ok {
+						fields[embeddedName] = embeddedType
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -0,0 +1,50 @@
+package eventschema
+
+import (
+	"strings"
+	"testing"
+)
+
+type testMoney struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type testOrderEvent struct {
+	OrderID int64     `json:"order_id"`
+	Status  string    `json:"status"`
+	Total   testMoney `json:"total"`
+	Note    *string   `json:"note,omitempty"`
+	Items   []int     `json:"items"`
+	ignored string
+}
+
+func TestCheckStruct(t *testing.T) {
+	tests := []struct {
+		name     string
+		contract string
+		problem  string
+	}{
+		{"matches", orderSchema, ""},
+		{"field not decoded", strings.Replace(orderSchema, `"note": {"type": "string"},`, `"note": {"type": "string"}, "gift": {"type": "boolean"},`, 1), "gift is in the contract but the struct doesn't decode it"},
+		{"field not in contract", strings.Replace(orderSchema, `"note": {"type": "string"},`, ``, 1), "note is decoded by the struct but not in the contract"},
+		{"type can't be held", strings.Replace(orderSchema, `"order_id": {"type": "integer", "minimum": 1}`, `"order_id": {"type": "number"}`, 1), "$.order_id: may be number, decoded into int64"},
+		{"nested type can't be held", strings.Replace(orderSchema, `"currency": {"type": "string", "pattern": "^[A-Z]{3}$"}`, `"currency": {"type": "integer"}`, 1), "$.total.currency: may be integer"},
+		{"array item type", strings.Replace(orderSchema, `"items": {"type": "integer"}`, `"items": {"type": "string"}`, 1), "$.items[]: may be string"},
+		{"required not described", strings.Replace(orderSchema, `["order_id", "status", "total"]`, `["order_id", "status", "total", "gift"]`, 1), "gift is required but not in the properties"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems := CheckStruct(mustParse(t, test.contract), testOrderEvent{})
+			if test.problem == "" {
+				if len(problems) > 0 {
+					t.Fatalf("unexpected problems: %v", problems)
+				}
+				return
+			}
+			if !strings.Contains(strings.Join(problems, "\n"), test.problem) {
+				t.Fatalf("got %v, want a problem about %q", problems, test.problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package carrier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fulfillment/config"
+	"fulfillment/models"
+	"time"
+)
+
+var (
+	ErrShipmentRejected = errors.New("shipment rejected by carrier")
+	ErrUnknownTracking  = errors.New("unknown tracking number")
+)
+
+type ShipmentRequest struct {
+	// Reference identifies the parcel to the carrier; booking the same reference again returns
+	// the label booked the first time
+	Reference       string
+	ShippingAddress string
+	Items           []models.ShipmentItem
+}
+
+type Label struct {
+	TrackingNumber string
+}
+
+type TrackingInfo struct {
+	// Status is one of the constant.ShipmentStatus values
+	Status    string
+	UpdatedAt time.Time
+}
+
+// Carrier books parcels with a shipping company and reports where they are. CreateShipment
+// returns ErrShipmentRejected when the carrier refuses the parcel, Track returns
+// ErrUnknownTracking for a tracking number the carrier doesn't know.
+type Carrier interface {
+	Name() string
+	CreateShipment(ctx context.Context, req ShipmentRequest) (Label, error)
+	Track(ctx context.Context, trackingNumber string) (TrackingInfo, error)
+}
+
+func NewCarrier(cfg config.CarrierConfig) (Carrier, error) {
+	switch cfg.Name {
+	case "fake":
+		return NewFakeCarrier(cfg.Fake.InTransitAfter, cfg.Fake.DeliveredAfter), nil
+	default:
+		return nil, fmt.Errorf("unknown carrier: %s", cfg.Name)
+	}
+}
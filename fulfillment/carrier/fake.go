@@ -0,0 +1,86 @@
+package carrier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	constant "fulfillment/infrastructure/constans"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultInTransitAfter = time.Minute
+	defaultDeliveredAfter = 5 * time.Minute
+)
+
+// FakeCarrier is a local carrier for development and tests. Parcels are reported in transit and
+// then delivered after fixed delays. The label time is encoded in the tracking number, so tracking
+// keeps working across restarts.
+type FakeCarrier struct {
+	mu             sync.Mutex
+	labels         map[string]Label
+	inTransitAfter time.Duration
+	deliveredAfter time.Duration
+}
+
+func NewFakeCarrier(inTransitAfter time.Duration, deliveredAfter time.Duration) *FakeCarrier {
+	if inTransitAfter <= 0 {
+		inTransitAfter = defaultInTransitAfter
+	}
+	if deliveredAfter <= inTransitAfter {
+		deliveredAfter = inTransitAfter + defaultDeliveredAfter
+	}
+	return &FakeCarrier{
+		labels:         make(map[string]Label),
+		inTransitAfter: inTransitAfter,
+		deliveredAfter: deliveredAfter,
+	}
+}
+
+func (c *FakeCarrier) Name() string {
+	return "fake"
+}
+
+func (c *FakeCarrier) CreateShipment(ctx context.Context, req ShipmentRequest) (Label, error) {
+	if len(req.Items) == 0 {
+		return Label{}, fmt.Errorf("%w: empty parcel", ErrShipmentRejected)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if label, ok := c.labels[req.Reference]; ok {
+		return label, nil
+	}
+	hash := sha256.Sum256([]byte(req.Reference))
+	label := Label{
+		TrackingNumber: fmt.Sprintf("FAKE-%s-%s", strconv.FormatInt(time.Now().UnixMilli(), 36), strings.ToUpper(hex.EncodeToString(hash[:4]))),
+	}
+	c.labels[req.Reference] = label
+	return label, nil
+}
+
+func (c *FakeCarrier) Track(ctx context.Context, trackingNumber string) (TrackingInfo, error) {
+	parts := strings.Split(trackingNumber, "-")
+	if len(parts) != 3 || parts[0] != "FAKE" {
+		return TrackingInfo{}, fmt.Errorf("%w: %s", ErrUnknownTracking, trackingNumber)
+	}
+	createdAt, err := strconv.ParseInt(parts[1], 36, 64)
+	if err != nil {
+		return TrackingInfo{}, fmt.Errorf("%w: %s", ErrUnknownTracking, trackingNumber)
+	}
+
+	labelTime := time.UnixMilli(createdAt)
+	elapsed := time.Since(labelTime)
+	switch {
+	case elapsed >= c.deliveredAfter:
+		return TrackingInfo{Status: constant.ShipmentStatusDelivered, UpdatedAt: labelTime.Add(c.deliveredAfter)}, nil
+	case elapsed >= c.inTransitAfter:
+		return TrackingInfo{Status: constant.ShipmentStatusInTransit, UpdatedAt: labelTime.Add(c.inTransitAfter)}, nil
+	default:
+		return TrackingInfo{Status: constant.ShipmentStatusShipped, UpdatedAt: labelTime}, nil
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fulfillment/cmd/fulfillment/usecase"
+	constant "fulfillment/infrastructure/constans"
+	"fulfillment/infrastructure/log"
+	"fulfillment/kafka"
+	"fulfillment/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type FulfillmentHandler struct {
+	FulfillmentUseCase *usecase.FulfillmentUseCase
+}
+
+func NewFulfillmentHandler(fulfillmentUseCase *usecase.FulfillmentUseCase) *FulfillmentHandler {
+	return &FulfillmentHandler{
+		FulfillmentUseCase: fulfillmentUseCase,
+	}
+}
+
+// HandleOrderCreated consumes the order.created topic.
+func (h *FulfillmentHandler) HandleOrderCreated(ctx context.Context, value []byte) error {
+	var event models.OrderCreatedEvent
+	if err := kafka.DecodeOrderEvent(value, kafka.EventTypeOrderCreated, 1, &event); err != nil {
+		// a malformed message will never succeed, park it on the dead-letter topic
+		return kafka.Permanent(err)
+	}
+
+	err := h.FulfillmentUseCase.ProcessOrderCreated(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.FulfillmentUseCase.ProcessOrderCreated got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HandlePaymentSucceeded consumes the payment.succeeded topic.
+func (h *FulfillmentHandler) HandlePaymentSucceeded(ctx context.Context, value []byte) error {
+	var event models.PaymentEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return kafka.Permanent(err)
+	}
+
+	err := h.FulfillmentUseCase.ProcessPaymentSucceeded(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.FulfillmentUseCase.ProcessPaymentSucceeded got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HandleOrderCancelled consumes the order.cancelled topic.
+func (h *FulfillmentHandler) HandleOrderCancelled(ctx context.Context, value []byte) error {
+	var event models.OrderCancelledEvent
+	if err := kafka.DecodeOrderEvent(value, kafka.EventTypeOrderCancelled, 1, &event); err != nil {
+		return kafka.Permanent(err)
+	}
+
+	err := h.FulfillmentUseCase.ProcessOrderCancelled(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.FulfillmentUseCase.ProcessOrderCancelled got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (h *FulfillmentHandler) GetShipmentsByOrderID(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	shipments, err := h.FulfillmentUseCase.GetShipmentsByOrderID(c.Request.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, constant.ErrShipmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Logger.WithFields(logrus.Fields{
+			"orderID": orderID,
+		}).Errorf("h.FulfillmentUseCase.GetShipmentsByOrderID got error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": shipments,
+	})
+}
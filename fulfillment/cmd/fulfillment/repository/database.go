@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	constant "fulfillment/infrastructure/constans"
+	"fulfillment/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetFulfillmentOrderForUpdateTx returns the fulfillment order, created when it doesn't exist yet,
+// and locks it until the transaction ends.
+func (r *FulfillmentRepository) GetFulfillmentOrderForUpdateTx(ctx context.Context, tx *gorm.DB, orderID int64, userID int64) (*models.FulfillmentOrder, error) {
+	err := tx.WithContext(ctx).Table("fulfillment_order").
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "order_id"}}, DoNothing: true}).
+		Create(&models.FulfillmentOrder{
+			OrderID: orderID,
+			UserID:  userID,
+			Items:   "[]",
+			Status:  constant.FulfillmentStatusWaiting,
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var order models.FulfillmentOrder
+	err = tx.WithContext(ctx).Table("fulfillment_order").Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *FulfillmentRepository) UpdateFulfillmentOrderTx(ctx context.Context, tx *gorm.DB, order *models.FulfillmentOrder) error {
+	err := tx.WithContext(ctx).Table("fulfillment_order").Where("order_id = ?", order.OrderID).Updates(map[string]interface{}{
+		"items":            order.Items,
+		"shipping_address": order.ShippingAddress,
+		"order_received":   order.OrderReceived,
+		"paid":             order.Paid,
+		"status":           order.Status,
+		"update_time":      time.Now(),
+	}).Error
+	return err
+}
+
+func (r *FulfillmentRepository) InsertShipmentsTx(ctx context.Context, tx *gorm.DB, shipments []models.Shipment) error {
+	return tx.WithContext(ctx).Table("shipment").Create(&shipments).Error
+}
+
+func (r *FulfillmentRepository) FindShipmentsByOrderID(ctx context.Context, orderID int64) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := r.Database.WithContext(ctx).Table("shipment").Where("order_id = ?", orderID).Order("parcel_no").Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+// FindShipmentsByStatus returns up to limit shipments in one of statuses, least recently updated
+// first, so every shipment gets its turn.
+func (r *FulfillmentRepository) FindShipmentsByStatus(ctx context.Context, statuses []string, limit int) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := r.Database.WithContext(ctx).Table("shipment").Where("status IN ?", statuses).Order("update_time, id").Limit(limit).Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+// UpdateShipment stores the new state of a shipment, provided its status is still fromStatus, and
+// reports whether it was; a shipment cancelled or moved on in the meantime is left alone.
+func (r *FulfillmentRepository) UpdateShipment(ctx context.Context, shipment *models.Shipment, fromStatus string) (bool, error) {
+	result := r.Database.WithContext(ctx).Table("shipment").Where("id = ? AND status = ?", shipment.ID, fromStatus).Updates(map[string]interface{}{
+		"carrier":         shipment.Carrier,
+		"tracking_number": shipment.TrackingNumber,
+		"status":          shipment.Status,
+		"shipped_time":    shipment.ShippedTime,
+		"delivered_time":  shipment.DeliveredTime,
+		"update_time":     time.Now(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindUnpublishedShipments returns up to limit shipments whose current status hasn't been
+// announced yet, least recently updated first.
+func (r *FulfillmentRepository) FindUnpublishedShipments(ctx context.Context, limit int) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := r.Database.WithContext(ctx).Table("shipment").Where("status <> published_status").Order("update_time, id").Limit(limit).Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+// MarkShipmentPublished records that status was announced, unless the shipment has moved on since.
+func (r *FulfillmentRepository) MarkShipmentPublished(ctx context.Context, shipmentID int64, status string) error {
+	return r.Database.WithContext(ctx).Table("shipment").Where("id = ? AND status = ?", shipmentID, status).Update("published_status", status).Error
+}
+
+// TouchShipment moves the shipment to the back of the FindShipmentsByStatus queue.
+func (r *FulfillmentRepository) TouchShipment(ctx context.Context, shipmentID int64) error {
+	return r.Database.WithContext(ctx).Table("shipment").Where("id = ?", shipmentID).Update("update_time", time.Now()).Error
+}
+
+// CancelPendingShipmentsTx cancels the shipments of the order that haven't been handed to the
+// carrier yet and returns them.
+func (r *FulfillmentRepository) CancelPendingShipmentsTx(ctx context.Context, tx *gorm.DB, orderID int64) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := tx.WithContext(ctx).Table("shipment").Model(&shipments).Clauses(clause.Returning{}).
+		Where("order_id = ? AND status = ?", orderID, constant.ShipmentStatusPending).
+		Updates(map[string]interface{}{
+			"status":      constant.ShipmentStatusCancelled,
+			"update_time": time.Now(),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type FulfillmentRepository struct {
+	Database *gorm.DB
+}
+
+func NewFulfillmentRepository(db *gorm.DB) *FulfillmentRepository {
+	return &FulfillmentRepository{
+		Database: db,
+	}
+}
+
+// WithTransaction runs fn in a transaction, committed when fn returns nil and rolled back otherwise.
+func (r *FulfillmentRepository) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.Database.WithContext(ctx).Transaction(fn)
+}
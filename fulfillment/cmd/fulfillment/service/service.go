@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fulfillment/cmd/fulfillment/repository"
+	constant "fulfillment/infrastructure/constans"
+	"fulfillment/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type FulfillmentService struct {
+	FulfillmentRepository *repository.FulfillmentRepository
+}
+
+func NewFulfillmentService(fulfillmentRepository *repository.FulfillmentRepository) *FulfillmentService {
+	return &FulfillmentService{
+		FulfillmentRepository: fulfillmentRepository,
+	}
+}
+
+// RecordOrder stores the items and address of an order and returns its fulfillment order.
+func (s *FulfillmentService) RecordOrder(ctx context.Context, event models.OrderCreatedEvent) (*models.FulfillmentOrder, error) {
+	items, err := json.Marshal(event.Items)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateFulfillmentOrder(ctx, event.OrderID, event.UserID, func(order *models.FulfillmentOrder) {
+		order.Items = string(items)
+		order.ShippingAddress = event.ShippingAddress
+		order.OrderReceived = true
+	})
+}
+
+// RecordPayment marks the order as paid and returns its fulfillment order.
+func (s *FulfillmentService) RecordPayment(ctx context.Context, event models.PaymentEvent) (*models.FulfillmentOrder, error) {
+	return s.updateFulfillmentOrder(ctx, event.OrderID, event.UserID, func(order *models.FulfillmentOrder) {
+		order.Paid = true
+	})
+}
+
+func (s *FulfillmentService) updateFulfillmentOrder(ctx context.Context, orderID int64, userID int64, update func(order *models.FulfillmentOrder)) (*models.FulfillmentOrder, error) {
+	var order *models.FulfillmentOrder
+	err := s.FulfillmentRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		order, err = s.FulfillmentRepository.GetFulfillmentOrderForUpdateTx(ctx, tx, orderID, userID)
+		if err != nil {
+			return err
+		}
+		update(order)
+		return s.FulfillmentRepository.UpdateFulfillmentOrderTx(ctx, tx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CreateShipments stores the parcels of a paid order, once: an order that is already shipping or
+// was cancelled in the meantime is left as it is.
+func (s *FulfillmentService) CreateShipments(ctx context.Context, orderID int64, split func(items []models.ShipmentItem) [][]models.ShipmentItem) error {
+	return s.FulfillmentRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		order, err := s.FulfillmentRepository.GetFulfillmentOrderForUpdateTx(ctx, tx, orderID, 0)
+		if err != nil {
+			return err
+		}
+		if order.Status != constant.FulfillmentStatusWaiting || !order.OrderReceived || !order.Paid {
+			return nil
+		}
+
+		var items []models.ShipmentItem
+		err = json.Unmarshal([]byte(order.Items), &items)
+		if err != nil {
+			return err
+		}
+		parcels := split(items)
+		shipments := make([]models.Shipment, 0, len(parcels))
+		for i, parcel := range parcels {
+			parcelJSON, err := json.Marshal(parcel)
+			if err != nil {
+				return err
+			}
+			shipments = append(shipments, models.Shipment{
+				OrderID:     order.OrderID,
+				UserID:      order.UserID,
+				ParcelNo:    i + 1,
+				ParcelCount: len(parcels),
+				Items:       string(parcelJSON),
+				Status:      constant.ShipmentStatusPending,
+				// nothing to announce until the parcel is handed to the carrier
+				PublishedStatus: constant.ShipmentStatusPending,
+			})
+		}
+		if len(shipments) > 0 {
+			err = s.FulfillmentRepository.InsertShipmentsTx(ctx, tx, shipments)
+			if err != nil {
+				return err
+			}
+		}
+
+		order.Status = constant.FulfillmentStatusShipping
+		return s.FulfillmentRepository.UpdateFulfillmentOrderTx(ctx, tx, order)
+	})
+}
+
+// CancelOrder stops a cancelled order from being shipped and returns the parcels that were
+// cancelled; parcels already handed to the carrier keep going.
+func (s *FulfillmentService) CancelOrder(ctx context.Context, event models.OrderCancelledEvent) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := s.FulfillmentRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		order, err := s.FulfillmentRepository.GetFulfillmentOrderForUpdateTx(ctx, tx, event.OrderID, event.UserID)
+		if err != nil {
+			return err
+		}
+		order.Status = constant.FulfillmentStatusCancelled
+		err = s.FulfillmentRepository.UpdateFulfillmentOrderTx(ctx, tx, order)
+		if err != nil {
+			return err
+		}
+		shipments, err = s.FulfillmentRepository.CancelPendingShipmentsTx(ctx, tx, event.OrderID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withParcelItems(shipments)
+}
+
+func (s *FulfillmentService) GetShipmentsByOrderID(ctx context.Context, orderID int64) ([]models.Shipment, error) {
+	shipments, err := s.FulfillmentRepository.FindShipmentsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(shipments) == 0 {
+		return nil, constant.ErrShipmentNotFound
+	}
+	return withParcelItems(shipments)
+}
+
+func (s *FulfillmentService) GetShipmentsByStatus(ctx context.Context, statuses []string, limit int) ([]models.Shipment, error) {
+	shipments, err := s.FulfillmentRepository.FindShipmentsByStatus(ctx, statuses, limit)
+	if err != nil {
+		return nil, err
+	}
+	return withParcelItems(shipments)
+}
+
+// UpdateShipmentStatus records the new status of a shipment, with the time it was shipped or
+// delivered. It returns ErrShipmentStatusChanged when the stored shipment no longer has the status
+// the caller saw, e.g. because the order was cancelled meanwhile.
+func (s *FulfillmentService) UpdateShipmentStatus(ctx context.Context, shipment *models.Shipment, status string, at time.Time) error {
+	fromStatus := shipment.Status
+	shipment.Status = status
+	switch status {
+	case constant.ShipmentStatusShipped:
+		shipment.ShippedTime = &at
+	case constant.ShipmentStatusDelivered:
+		shipment.DeliveredTime = &at
+	}
+	updated, err := s.FulfillmentRepository.UpdateShipment(ctx, shipment, fromStatus)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return constant.ErrShipmentStatusChanged
+	}
+	return nil
+}
+
+func (s *FulfillmentService) GetUnpublishedShipments(ctx context.Context, limit int) ([]models.Shipment, error) {
+	shipments, err := s.FulfillmentRepository.FindUnpublishedShipments(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return withParcelItems(shipments)
+}
+
+func (s *FulfillmentService) MarkShipmentPublished(ctx context.Context, shipment *models.Shipment) error {
+	return s.FulfillmentRepository.MarkShipmentPublished(ctx, shipment.ID, shipment.Status)
+}
+
+func (s *FulfillmentService) TouchShipment(ctx context.Context, shipmentID int64) error {
+	return s.FulfillmentRepository.TouchShipment(ctx, shipmentID)
+}
+
+func withParcelItems(shipments []models.Shipment) ([]models.Shipment, error) {
+	for i := range shipments {
+		err := json.Unmarshal([]byte(shipments[i].Items), &shipments[i].ParcelItems)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return shipments, nil
+}
@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fulfillment/carrier"
+	"fulfillment/cmd/fulfillment/service"
+	constant "fulfillment/infrastructure/constans"
+	"fulfillment/infrastructure/log"
+	"fulfillment/kafka"
+	"fulfillment/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxItemsPerParcel = 10
+	defaultCarrierTimeout    = 5 * time.Second
+)
+
+type FulfillmentUseCase struct {
+	FulfillmentService *service.FulfillmentService
+	Carrier            carrier.Carrier
+	KafkaProducer      *kafka.KafkaProducer
+	CarrierTimeout     time.Duration
+	MaxItemsPerParcel  int
+}
+
+func NewFulfillmentUseCase(fulfillmentService *service.FulfillmentService, shippingCarrier carrier.Carrier, kafkaProducer *kafka.KafkaProducer, carrierTimeout time.Duration, maxItemsPerParcel int) *FulfillmentUseCase {
+	if carrierTimeout <= 0 {
+		carrierTimeout = defaultCarrierTimeout
+	}
+	if maxItemsPerParcel <= 0 {
+		maxItemsPerParcel = defaultMaxItemsPerParcel
+	}
+	return &FulfillmentUseCase{
+		FulfillmentService: fulfillmentService,
+		Carrier:            shippingCarrier,
+		KafkaProducer:      kafkaProducer,
+		CarrierTimeout:     carrierTimeout,
+		MaxItemsPerParcel:  maxItemsPerParcel,
+	}
+}
+
+// ProcessOrderCreated records what to ship for a new order and ships it if it's already paid.
+func (uc *FulfillmentUseCase) ProcessOrderCreated(ctx context.Context, event models.OrderCreatedEvent) error {
+	order, err := uc.FulfillmentService.RecordOrder(ctx, event)
+	if err != nil {
+		return err
+	}
+	return uc.ship(ctx, order)
+}
+
+// ProcessPaymentSucceeded ships a paid order once the order itself is known.
+func (uc *FulfillmentUseCase) ProcessPaymentSucceeded(ctx context.Context, event models.PaymentEvent) error {
+	order, err := uc.FulfillmentService.RecordPayment(ctx, event)
+	if err != nil {
+		return err
+	}
+	return uc.ship(ctx, order)
+}
+
+// ProcessOrderCancelled cancels the parcels of the order that haven't been handed to the carrier.
+// Cancellations that can't be published here are sent by PublishShipmentUpdates.
+func (uc *FulfillmentUseCase) ProcessOrderCancelled(ctx context.Context, event models.OrderCancelledEvent) error {
+	shipments, err := uc.FulfillmentService.CancelOrder(ctx, event)
+	if err != nil {
+		return err
+	}
+	for i := range shipments {
+		err = uc.publishShipmentEvent(ctx, &shipments[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *FulfillmentUseCase) GetShipmentsByOrderID(ctx context.Context, orderID int64) ([]models.Shipment, error) {
+	return uc.FulfillmentService.GetShipmentsByOrderID(ctx, orderID)
+}
+
+// ship splits a paid order into parcels and hands them to the carrier. Redelivered events find the
+// parcels already created; pending ones are picked up again by DispatchPendingShipments.
+func (uc *FulfillmentUseCase) ship(ctx context.Context, order *models.FulfillmentOrder) error {
+	if order.Status != constant.FulfillmentStatusWaiting || !order.OrderReceived || !order.Paid {
+		return nil
+	}
+	err := uc.FulfillmentService.CreateShipments(ctx, order.OrderID, uc.splitIntoParcels)
+	if err != nil {
+		return err
+	}
+	shipments, err := uc.FulfillmentService.GetShipmentsByOrderID(ctx, order.OrderID)
+	if errors.Is(err, constant.ErrShipmentNotFound) {
+		// nothing to ship
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for i := range shipments {
+		if shipments[i].Status != constant.ShipmentStatusPending {
+			continue
+		}
+		err = uc.dispatch(ctx, &shipments[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DispatchPendingShipments retries handing parcels to the carrier that couldn't be handed over
+// when their order was paid. It returns the number of shipments looked at.
+func (uc *FulfillmentUseCase) DispatchPendingShipments(ctx context.Context, limit int) (int, error) {
+	shipments, err := uc.FulfillmentService.GetShipmentsByStatus(ctx, []string{constant.ShipmentStatusPending}, limit)
+	if err != nil {
+		return 0, err
+	}
+	for i := range shipments {
+		err = uc.dispatch(ctx, &shipments[i])
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"shipmentID": shipments[i].ID,
+				"orderID":    shipments[i].OrderID,
+			}).Errorf("uc.dispatch got error: %v", err)
+		}
+	}
+	return len(shipments), nil
+}
+
+// TrackShipments asks the carrier where the parcels on their way are and publishes every change.
+// It returns the number of shipments looked at.
+func (uc *FulfillmentUseCase) TrackShipments(ctx context.Context, limit int) (int, error) {
+	shipments, err := uc.FulfillmentService.GetShipmentsByStatus(ctx, []string{constant.ShipmentStatusShipped, constant.ShipmentStatusInTransit}, limit)
+	if err != nil {
+		return 0, err
+	}
+	for i := range shipments {
+		err = uc.track(ctx, &shipments[i])
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"shipmentID":     shipments[i].ID,
+				"trackingNumber": shipments[i].TrackingNumber,
+			}).Errorf("uc.track got error: %v", err)
+		}
+	}
+	return len(shipments), nil
+}
+
+// PublishShipmentUpdates announces the shipment statuses that were stored but couldn't be published
+// at the time. It returns the number of shipments looked at.
+func (uc *FulfillmentUseCase) PublishShipmentUpdates(ctx context.Context, limit int) (int, error) {
+	shipments, err := uc.FulfillmentService.GetUnpublishedShipments(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	for i := range shipments {
+		err = uc.publishShipmentEvent(ctx, &shipments[i])
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"shipmentID": shipments[i].ID,
+				"orderID":    shipments[i].OrderID,
+			}).Errorf("uc.publishShipmentEvent got error: %v", err)
+		}
+	}
+	return len(shipments), nil
+}
+
+// dispatch books the parcel with the carrier. The shipped status is only stored when the parcel
+// is still pending, so a parcel cancelled while it was being booked stays cancelled. The event goes
+// out after the status is stored; PublishShipmentUpdates sends it when publishing fails here.
+func (uc *FulfillmentUseCase) dispatch(ctx context.Context, shipment *models.Shipment) error {
+	carrierCtx, cancel := context.WithTimeout(ctx, uc.CarrierTimeout)
+	defer cancel()
+	label, err := uc.Carrier.CreateShipment(carrierCtx, carrier.ShipmentRequest{
+		Reference: fmt.Sprintf("order-%d-parcel-%d", shipment.OrderID, shipment.ParcelNo),
+		Items:     shipment.ParcelItems,
+	})
+	if err != nil {
+		// try again later, after the other pending parcels
+		touchErr := uc.FulfillmentService.TouchShipment(ctx, shipment.ID)
+		if touchErr != nil {
+			log.Logger.Errorf("uc.FulfillmentService.TouchShipment got error: %v", touchErr)
+		}
+		return err
+	}
+
+	shipment.Carrier = uc.Carrier.Name()
+	shipment.TrackingNumber = label.TrackingNumber
+	err = uc.FulfillmentService.UpdateShipmentStatus(ctx, shipment, constant.ShipmentStatusShipped, time.Now())
+	if errors.Is(err, constant.ErrShipmentStatusChanged) {
+		log.Logger.WithFields(logrus.Fields{
+			"shipmentID":     shipment.ID,
+			"orderID":        shipment.OrderID,
+			"trackingNumber": label.TrackingNumber,
+		}).Warn("shipment stopped being pending while it was booked, the carrier label is left unused")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return uc.publishShipmentEvent(ctx, shipment)
+}
+
+func (uc *FulfillmentUseCase) track(ctx context.Context, shipment *models.Shipment) error {
+	carrierCtx, cancel := context.WithTimeout(ctx, uc.CarrierTimeout)
+	defer cancel()
+	info, err := uc.Carrier.Track(carrierCtx, shipment.TrackingNumber)
+	if err != nil {
+		touchErr := uc.FulfillmentService.TouchShipment(ctx, shipment.ID)
+		if touchErr != nil {
+			log.Logger.Errorf("uc.FulfillmentService.TouchShipment got error: %v", touchErr)
+		}
+		if errors.Is(err, carrier.ErrUnknownTracking) {
+			log.Logger.WithFields(logrus.Fields{
+				"shipmentID":     shipment.ID,
+				"trackingNumber": shipment.TrackingNumber,
+			}).Warnf("carrier doesn't know the shipment: %v", err)
+			return nil
+		}
+		return err
+	}
+
+	if constant.ShipmentStatusRank[info.Status] <= constant.ShipmentStatusRank[shipment.Status] {
+		return uc.FulfillmentService.TouchShipment(ctx, shipment.ID)
+	}
+	err = uc.FulfillmentService.UpdateShipmentStatus(ctx, shipment, info.Status, info.UpdatedAt)
+	if errors.Is(err, constant.ErrShipmentStatusChanged) {
+		// another tracker got there first
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return uc.publishShipmentEvent(ctx, shipment)
+}
+
+// splitIntoParcels packs the items into parcels of at most MaxItemsPerParcel units, in order,
+// splitting an item over several parcels when needed.
+func (uc *FulfillmentUseCase) splitIntoParcels(items []models.ShipmentItem) [][]models.ShipmentItem {
+	var parcels [][]models.ShipmentItem
+	var parcel []models.ShipmentItem
+	space := uc.MaxItemsPerParcel
+	for _, item := range items {
+		remaining := item.Quantity
+		for remaining > 0 {
+			quantity := min(remaining, space)
+			parcel = append(parcel, models.ShipmentItem{ProductID: item.ProductID, Quantity: quantity})
+			remaining -= quantity
+			space -= quantity
+			if space == 0 {
+				parcels = append(parcels, parcel)
+				parcel = nil
+				space = uc.MaxItemsPerParcel
+			}
+		}
+	}
+	if len(parcel) > 0 {
+		parcels = append(parcels, parcel)
+	}
+	return parcels
+}
+
+func (uc *FulfillmentUseCase) publishShipmentEvent(ctx context.Context, shipment *models.Shipment) error {
+	event := models.ShipmentEvent{
+		ShipmentID:     shipment.ID,
+		OrderID:        shipment.OrderID,
+		UserID:         shipment.UserID,
+		ParcelNo:       shipment.ParcelNo,
+		ParcelCount:    shipment.ParcelCount,
+		Items:          shipment.ParcelItems,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		Status:         shipment.Status,
+		OccurredAt:     time.Now().Format(time.RFC3339Nano),
+	}
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	err = uc.KafkaProducer.Publish(ctx, kafka.TopicShipmentUpdated, fmt.Sprintf("order-%d", shipment.OrderID), value)
+	if err != nil {
+		return err
+	}
+	return uc.FulfillmentService.MarkShipmentPublished(ctx, shipment)
+}
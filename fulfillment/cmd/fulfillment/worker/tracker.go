@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"fulfillment/cmd/fulfillment/usecase"
+	"fulfillment/config"
+	"fulfillment/infrastructure/log"
+	"time"
+)
+
+const (
+	defaultTrackingInterval = 30 * time.Second
+	defaultTrackingBatch    = 100
+)
+
+// ShipmentTracker periodically hands pending parcels to the carrier, asks the carrier for the
+// status of the parcels on their way and publishes the updates that couldn't be published before.
+type ShipmentTracker struct {
+	FulfillmentUseCase *usecase.FulfillmentUseCase
+	Interval           time.Duration
+	BatchSize          int
+}
+
+func NewShipmentTracker(fulfillmentUseCase *usecase.FulfillmentUseCase, cfg config.FulfillmentConfig) *ShipmentTracker {
+	tracker := &ShipmentTracker{
+		FulfillmentUseCase: fulfillmentUseCase,
+		Interval:           cfg.TrackingInterval,
+		BatchSize:          cfg.TrackingBatch,
+	}
+	if tracker.Interval <= 0 {
+		tracker.Interval = defaultTrackingInterval
+	}
+	if tracker.BatchSize <= 0 {
+		tracker.BatchSize = defaultTrackingBatch
+	}
+	return tracker
+}
+
+// Start runs until ctx is cancelled.
+func (t *ShipmentTracker) Start(ctx context.Context) {
+	log.Logger.Info("Shipment tracker started")
+	for {
+		_, err := t.FulfillmentUseCase.DispatchPendingShipments(ctx, t.BatchSize)
+		if err != nil {
+			log.Logger.Errorf("t.FulfillmentUseCase.DispatchPendingShipments got error: %v", err)
+		}
+		_, err = t.FulfillmentUseCase.TrackShipments(ctx, t.BatchSize)
+		if err != nil {
+			log.Logger.Errorf("t.FulfillmentUseCase.TrackShipments got error: %v", err)
+		}
+		_, err = t.FulfillmentUseCase.PublishShipmentUpdates(ctx, t.BatchSize)
+		if err != nil {
+			log.Logger.Errorf("t.FulfillmentUseCase.PublishShipmentUpdates got error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Logger.Info("Shipment tracker stopped")
+			return
+		case <-time.After(t.Interval):
+		}
+	}
+}
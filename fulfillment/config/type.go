@@ -0,0 +1,57 @@
+package config
+
+import "time"
+
+type Config struct {
+	App         AppConfig         `yaml:"app" validate:"required"`
+	Database    DatabaseConfig    `yaml:"database" validate:"required"`
+	Kafka       KafkaConfig       `yaml:"kafka" validate:"required"`
+	Carrier     CarrierConfig     `yaml:"carrier" validate:"required"`
+	Fulfillment FulfillmentConfig `yaml:"fulfillment"`
+}
+
+type AppConfig struct {
+	Port string `yaml:"port" validate:"required"`
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host" validate:"required"`
+	Port     string `yaml:"port" validate:"required"`
+	User     string `yaml:"user" validate:"required"`
+	Password string `yaml:"password" validate:"required"`
+	Name     string `yaml:"name" validate:"required"`
+}
+
+type KafkaConfig struct {
+	Brokers       []string            `yaml:"brokers" validate:"required"`
+	ConsumerGroup string              `yaml:"consumer_group" mapstructure:"consumer_group" validate:"required"`
+	Consumer      KafkaConsumerConfig `yaml:"consumer"`
+}
+
+type KafkaConsumerConfig struct {
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`
+}
+
+type CarrierConfig struct {
+	Name    string        `yaml:"name" validate:"required"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Fake configures the local carrier used when Name is "fake"
+	Fake FakeCarrierConfig `yaml:"fake"`
+}
+
+type FakeCarrierConfig struct {
+	// InTransitAfter and DeliveredAfter are how long after the label is created a parcel is
+	// reported in transit and delivered
+	InTransitAfter time.Duration `yaml:"in_transit_after" mapstructure:"in_transit_after"`
+	DeliveredAfter time.Duration `yaml:"delivered_after" mapstructure:"delivered_after"`
+}
+
+type FulfillmentConfig struct {
+	// MaxItemsPerParcel is the number of units packed into one parcel, an order with more units is
+	// split into several parcels
+	MaxItemsPerParcel int `yaml:"max_items_per_parcel" mapstructure:"max_items_per_parcel"`
+	// TrackingInterval is how often the carrier is asked for the status of parcels on their way
+	TrackingInterval time.Duration `yaml:"tracking_interval" mapstructure:"tracking_interval"`
+	TrackingBatch    int           `yaml:"tracking_batch" mapstructure:"tracking_batch"`
+}
@@ -0,0 +1,32 @@
+create table fulfillment_order (
+    order_id bigint primary key,
+    user_id bigint not null,
+    items text not null default '[]',
+    shipping_address text not null default '',
+    order_received boolean not null default false,
+    paid boolean not null default false,
+    status varchar(20) not null,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create table shipment (
+    id bigserial primary key,
+    order_id bigint not null references fulfillment_order(order_id),
+    user_id bigint not null,
+    parcel_no integer not null,
+    parcel_count integer not null,
+    items text not null,
+    carrier varchar(50),
+    tracking_number varchar(100),
+    status varchar(20) not null,
+    published_status varchar(20) not null default '',
+    shipped_time timestamp,
+    delivered_time timestamp,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp,
+    constraint uq_shipment_order_parcel unique (order_id, parcel_no)
+);
+
+create index idx_shipment_status on shipment (status, update_time, id);
+create index idx_shipment_unpublished on shipment (update_time, id) where status <> published_status;
@@ -0,0 +1,8 @@
+package constant
+
+import "errors"
+
+var (
+	ErrShipmentNotFound      = errors.New("shipment not found")
+	ErrShipmentStatusChanged = errors.New("shipment status changed")
+)
@@ -0,0 +1,29 @@
+package constant
+
+// A shipment is pending until the carrier accepts it, then moves from shipped through in_transit
+// to delivered. Only a pending shipment can be cancelled.
+const (
+	ShipmentStatusPending   = "pending"
+	ShipmentStatusShipped   = "shipped"
+	ShipmentStatusInTransit = "in_transit"
+	ShipmentStatusDelivered = "delivered"
+	ShipmentStatusCancelled = "cancelled"
+)
+
+// ShipmentStatusRank orders the statuses a shipment goes through, so a status reported late never
+// moves a shipment back.
+var ShipmentStatusRank = map[string]int{
+	ShipmentStatusPending:   0,
+	ShipmentStatusShipped:   1,
+	ShipmentStatusInTransit: 2,
+	ShipmentStatusDelivered: 3,
+	ShipmentStatusCancelled: 3,
+}
+
+// A fulfillment order collects what the order and payment events tell about an order; it is
+// ready to ship once both the order and its successful payment are known.
+const (
+	FulfillmentStatusWaiting   = "waiting"
+	FulfillmentStatusShipping  = "shipping"
+	FulfillmentStatusCancelled = "cancelled"
+)
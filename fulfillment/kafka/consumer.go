@@ -0,0 +1,40 @@
+package kafka
+
+import (
+	"fulfillment/infrastructure/log"
+	"kafkaconsumer"
+	"time"
+)
+
+const DeadLetterTopicSuffix = kafkaconsumer.DeadLetterTopicSuffix
+
+// MessageHandler processes the value of one message. Returning an error retries the message until
+// it is handled; wrap the error with Permanent to send it to the dead-letter topic instead.
+type MessageHandler = kafkaconsumer.MessageHandler
+
+// KafkaConsumer runs one consumer group reader per registered topic, see kafkaconsumer.Consumer.
+type KafkaConsumer = kafkaconsumer.Consumer
+
+type ConsumerConfig struct {
+	Brokers        []string
+	GroupID        string
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Permanent marks an error that retrying can't fix, such as a message that can't be decoded.
+func Permanent(err error) error {
+	return kafkaconsumer.Permanent(err)
+}
+
+// NewKafkaConsumer returns a consumer that parks the messages it can't handle on their
+// dead-letter topic with producer.
+func NewKafkaConsumer(cfg ConsumerConfig, producer *KafkaProducer) *KafkaConsumer {
+	return kafkaconsumer.New(kafkaconsumer.Config{
+		Brokers:        cfg.Brokers,
+		GroupID:        cfg.GroupID,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		Logger:         log.Logger,
+	}, producer.writer)
+}
@@ -0,0 +1,43 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Types of the order service events consumed here.
+const (
+	EventTypeOrderCreated   = "OrderCreated"
+	EventTypeOrderCancelled = "OrderCancelled"
+)
+
+// Envelope wraps the events published by the order service.
+type Envelope struct {
+	EventID       string          `json:"event_id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// DecodeOrderEvent reads the payload of an order service event of eventType, up to maxVersion, into
+// event. Messages published before events had an envelope are the payload itself.
+func DecodeOrderEvent(value []byte, eventType string, maxVersion int, event interface{}) error {
+	var envelope Envelope
+	err := json.Unmarshal(value, &envelope)
+	if err != nil {
+		return err
+	}
+	if envelope.Type == "" || len(envelope.Payload) == 0 {
+		return json.Unmarshal(value, event)
+	}
+	if envelope.Type != eventType {
+		return fmt.Errorf("unexpected event type %q, want %q", envelope.Type, eventType)
+	}
+	if envelope.Version > maxVersion {
+		return fmt.Errorf("unsupported %s version %d", envelope.Type, envelope.Version)
+	}
+	return json.Unmarshal(envelope.Payload, event)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fulfillment/carrier"
+	"fulfillment/cmd/fulfillment/handler"
+	"fulfillment/cmd/fulfillment/repository"
+	"fulfillment/cmd/fulfillment/resource"
+	"fulfillment/cmd/fulfillment/service"
+	"fulfillment/cmd/fulfillment/usecase"
+	"fulfillment/cmd/fulfillment/worker"
+	"fulfillment/config"
+	"fulfillment/infrastructure/log"
+	"fulfillment/kafka"
+	routes "fulfillment/router"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	cfg := config.LoadConfig()
+	db := resource.InitDB(&cfg)
+
+	log.SetupLoger()
+
+	shippingCarrier, err := carrier.NewCarrier(cfg.Carrier)
+	if err != nil {
+		log.Logger.Fatalf("carrier.NewCarrier got error: %v", err)
+	}
+	kafkaProducer := kafka.NewKafkaProducer(cfg.Kafka.Brokers)
+	defer kafkaProducer.Close()
+
+	fulfillmentRepository := repository.NewFulfillmentRepository(db)
+	fulfillmentService := service.NewFulfillmentService(fulfillmentRepository)
+	fulfillmentUseCase := usecase.NewFulfillmentUseCase(fulfillmentService, shippingCarrier, kafkaProducer, cfg.Carrier.Timeout, cfg.Fulfillment.MaxItemsPerParcel)
+	fulfillmentHandler := handler.NewFulfillmentHandler(fulfillmentUseCase)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kafkaConsumer := kafka.NewKafkaConsumer(kafka.ConsumerConfig{
+		Brokers:        cfg.Kafka.Brokers,
+		GroupID:        cfg.Kafka.ConsumerGroup,
+		InitialBackoff: cfg.Kafka.Consumer.InitialBackoff,
+		MaxBackoff:     cfg.Kafka.Consumer.MaxBackoff,
+	}, kafkaProducer)
+	kafkaConsumer.Register(kafka.TopicOrderCreated, fulfillmentHandler.HandleOrderCreated)
+	kafkaConsumer.Register(kafka.TopicPaymentSucceeded, fulfillmentHandler.HandlePaymentSucceeded)
+	kafkaConsumer.Register(kafka.TopicOrderCancelled, fulfillmentHandler.HandleOrderCancelled)
+	kafkaConsumer.Start(ctx)
+	defer kafkaConsumer.Close()
+
+	shipmentTracker := worker.NewShipmentTracker(fulfillmentUseCase, cfg.Fulfillment)
+	go shipmentTracker.Start(ctx)
+
+	port := cfg.App.Port
+	router := gin.Default()
+
+	routes.SetupRouter(router, *fulfillmentHandler)
+
+	router.Run(":" + port)
+
+	log.Logger.Printf("Server Running on port: %s", port)
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+)
+
+// FulfillmentOrder is an order as far as fulfillment knows it. The order and payment events may
+// arrive in any order, each fills in its part.
+type FulfillmentOrder struct {
+	OrderID         int64     `json:"order_id" gorm:"primaryKey"`
+	UserID          int64     `json:"user_id"`
+	Items           string    `json:"items"`
+	ShippingAddress string    `json:"shipping_address"`
+	OrderReceived   bool      `json:"order_received"`
+	Paid            bool      `json:"paid"`
+	Status          string    `json:"status"`
+	CreateTime      time.Time `json:"create_time" gorm:"autoCreateTime"`
+	UpdateTime      time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+// Shipment is one parcel of an order.
+type Shipment struct {
+	ID             int64          `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID        int64          `json:"order_id"`
+	UserID         int64          `json:"user_id"`
+	ParcelNo       int            `json:"parcel_no"`
+	ParcelCount    int            `json:"parcel_count"`
+	Items          string         `json:"-"`
+	ParcelItems    []ShipmentItem `json:"items" gorm:"-"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number"`
+	Status         string         `json:"status"`
+	// PublishedStatus is the last status announced on shipment.updated
+	PublishedStatus string     `json:"-"`
+	ShippedTime     *time.Time `json:"shipped_time"`
+	DeliveredTime   *time.Time `json:"delivered_time"`
+	CreateTime      time.Time  `json:"create_time" gorm:"autoCreateTime"`
+	UpdateTime      time.Time  `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+type ShipmentItem struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+// OrderCreatedEvent is published by the order service on the order.created topic.
+type OrderCreatedEvent struct {
+	OrderID         int64          `json:"order_id"`
+	UserID          int64          `json:"user_id"`
+	ShippingAddress string         `json:"shipping_address"`
+	Items           []ShipmentItem `json:"items"`
+}
+
+// OrderCancelledEvent is published by the order service on the order.cancelled topic.
+type OrderCancelledEvent struct {
+	OrderID int64 `json:"order_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+// PaymentEvent is published by the payment service on payment.succeeded.
+type PaymentEvent struct {
+	OrderID int64  `json:"order_id"`
+	UserID  int64  `json:"user_id"`
+	Status  string `json:"status"`
+}
+
+// ShipmentEvent is published on shipment.updated every time a shipment changes status.
+type ShipmentEvent struct {
+	ShipmentID     int64          `json:"shipment_id"`
+	OrderID        int64          `json:"order_id"`
+	UserID         int64          `json:"user_id"`
+	ParcelNo       int            `json:"parcel_no"`
+	ParcelCount    int            `json:"parcel_count"`
+	Items          []ShipmentItem `json:"items"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number"`
+	Status         string         `json:"status"`
+	OccurredAt     string         `json:"occurred_at"`
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"fulfillment/cmd/fulfillment/handler"
+	"fulfillment/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRouter(router *gin.Engine, fulfillmentHandler handler.FulfillmentHandler) {
+	router.Use(middleware.RequestLogger())
+	router.GET("/v1/shipment/order/:order_id", fulfillmentHandler.GetShipmentsByOrderID)
+}
@@ -0,0 +1,200 @@
+// Package idempotency is the Idempotency-Key middleware shared by the services that expose
+// mutating endpoints clients may retry.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	KeyHeader       = "Idempotency-Key"
+	ReplayedHeader  = "Idempotent-Replayed"
+	maxKeyLength    = 255
+	defaultTTL      = 24 * time.Hour
+	defaultLockTTL  = 30 * time.Second
+	defaultErrorKey = "error"
+)
+
+var (
+	cacheKeyResponse = "idempotency:%s:%s:%s"
+	cacheKeyLock     = "idempotency_lock:%s:%s:%s"
+)
+
+type storedResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Config is how a service runs the middleware. ErrorKey is the key of the message in the JSON body
+// of rejected requests, so errors look like the rest of the service's API.
+type Config struct {
+	TTL      time.Duration
+	LockTTL  time.Duration
+	ErrorKey string
+	Logger   *logrus.Logger
+}
+
+// Middleware makes a mutating endpoint safe to retry. The first request with a given
+// Idempotency-Key header runs normally and, unless it fails with a 5xx, its status and body are
+// kept for cfg.TTL; retries within that window get the same response without running the handler.
+// A retry that arrives while the first request is still running gets 409, and reusing a key with a
+// different body gets 422. Requests without the header are not affected.
+func Middleware(rdb *redis.Client, cfg Config) gin.HandlerFunc {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	lockTTL := cfg.LockTTL
+	if lockTTL <= 0 {
+		lockTTL = defaultLockTTL
+	}
+	errorKey := cfg.ErrorKey
+	if errorKey == "" {
+		errorKey = defaultErrorKey
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(KeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				errorKey: "Invalid Idempotency-Key",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				errorKey: "Invalid Input",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := sha256.Sum256(body)
+
+		// keep the cache writes alive when the request context times out
+		ctx := context.WithoutCancel(c.Request.Context())
+		responseKey := fmt.Sprintf(cacheKeyResponse, c.Request.Method, c.FullPath(), key)
+		lockKey := fmt.Sprintf(cacheKeyLock, c.Request.Method, c.FullPath(), key)
+		requestLog := logrus.Fields{
+			"idempotency_key": key,
+			"path":            c.FullPath(),
+		}
+
+		response := storedResponse{Fingerprint: hex.EncodeToString(fingerprint[:])}
+		if replayResponse(c, rdb, logger, errorKey, responseKey, response.Fingerprint) {
+			return
+		}
+
+		isLocked, err := rdb.SetNX(ctx, lockKey, 1, lockTTL).Result()
+		if err != nil {
+			// without redis the endpoint still works, it just isn't protected
+			logger.WithFields(requestLog).Errorf("rdb.SetNX got error: %v", err)
+			c.Next()
+			return
+		}
+		if !isLocked {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+				errorKey: "A request with this Idempotency-Key is already in progress",
+			})
+			return
+		}
+		defer rdb.Del(ctx, lockKey)
+
+		// the first request may have finished between the lookup and taking the lock
+		if replayResponse(c, rdb, logger, errorKey, responseKey, response.Fingerprint) {
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+		c.Next()
+
+		response.Status = recorder.Status()
+		if response.Status >= http.StatusInternalServerError {
+			return
+		}
+		response.ContentType = recorder.Header().Get("Content-Type")
+		response.Body = recorder.body.Bytes()
+		responseJSON, err := json.Marshal(response)
+		if err != nil {
+			logger.WithFields(requestLog).Errorf("json.Marshal got error: %v", err)
+			return
+		}
+		err = rdb.Set(ctx, responseKey, responseJSON, ttl).Err()
+		if err != nil {
+			logger.WithFields(requestLog).Errorf("rdb.Set got error: %v", err)
+		}
+	}
+}
+
+// replayResponse writes the stored response of responseKey, if any, and aborts the chain.
+func replayResponse(c *gin.Context, rdb *redis.Client, logger *logrus.Logger, errorKey string, responseKey string, fingerprint string) bool {
+	responseJSON, err := rdb.Get(c.Request.Context(), responseKey).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.WithFields(logrus.Fields{
+				"key": responseKey,
+			}).Errorf("rdb.Get got error: %v", err)
+		}
+		return false
+	}
+
+	var response storedResponse
+	err = json.Unmarshal(responseJSON, &response)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"key": responseKey,
+		}).Errorf("json.Unmarshal got error: %v", err)
+		return false
+	}
+	if response.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			errorKey: "Idempotency-Key was already used with a different request",
+		})
+		return true
+	}
+
+	c.Header(ReplayedHeader, "true")
+	c.Data(response.Status, response.ContentType, response.Body)
+	c.Abort()
+	return true
+}
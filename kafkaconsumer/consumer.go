@@ -0,0 +1,225 @@
+// Package kafkaconsumer is the consumer group runner shared by the services that react to events:
+// per-topic handlers, at-least-once processing with manual offset commits, retries with capped
+// backoff and a dead-letter topic for the messages that can never be handled.
+package kafkaconsumer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DeadLetterTopicSuffix = ".dlq"
+
+	defaultInitialBackoff = 200 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// MessageHandler processes the value of one message. Returning an error retries the message until
+// it is handled; wrap the error with Permanent to send it to the dead-letter topic instead.
+type MessageHandler func(ctx context.Context, value []byte) error
+
+type Config struct {
+	Brokers        []string
+	GroupID        string
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Logger         *logrus.Logger
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks an error that retrying can't fix, such as a message that can't be decoded.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Consumer runs one consumer group reader per registered topic. Messages are processed at least
+// once: an offset is committed only after its message was handled or parked on the dead-letter
+// topic <topic>.dlq.
+type Consumer struct {
+	cfg         Config
+	logger      *logrus.Logger
+	deadLetters *kafka.Writer
+	handlers    map[string]MessageHandler
+	readers     []*kafka.Reader
+	wg          sync.WaitGroup
+}
+
+// New returns a consumer that parks failed messages with the deadLetters writer. The writer must
+// not set a Topic, messages carry their own.
+func New(cfg Config, deadLetters *kafka.Writer) *Consumer {
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return &Consumer{
+		cfg:         cfg,
+		logger:      logger,
+		deadLetters: deadLetters,
+		handlers:    map[string]MessageHandler{},
+	}
+}
+
+// Register sets the handler of a topic. It must be called before Start.
+func (c *Consumer) Register(topic string, handler MessageHandler) {
+	c.handlers[topic] = handler
+}
+
+func (c *Consumer) Start(ctx context.Context) {
+	for topic, handler := range c.handlers {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers: c.cfg.Brokers,
+			GroupID: c.cfg.GroupID,
+			Topic:   topic,
+		})
+		c.readers = append(c.readers, reader)
+
+		c.wg.Add(1)
+		go func(reader *kafka.Reader, handler MessageHandler) {
+			defer c.wg.Done()
+			c.consume(ctx, reader, handler)
+		}(reader, handler)
+	}
+	c.logger.WithFields(logrus.Fields{
+		"groupID": c.cfg.GroupID,
+		"topics":  len(c.handlers),
+	}).Info("Kafka consumer started")
+}
+
+func (c *Consumer) consume(ctx context.Context, reader *kafka.Reader, handler MessageHandler) {
+	backoff := c.cfg.InitialBackoff
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			// io.EOF means the reader was closed
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+			c.logger.Errorf("reader.FetchMessage got error: %v", err)
+			if err := sleep(ctx, backoff); err != nil {
+				return
+			}
+			backoff = min(backoff*2, c.cfg.MaxBackoff)
+			continue
+		}
+		backoff = c.cfg.InitialBackoff
+
+		err = c.handle(ctx, msg, handler)
+		if err != nil {
+			// only happens when ctx is done, the message is redelivered after a restart
+			return
+		}
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			c.logger.WithFields(messageFields(msg)).Errorf("reader.CommitMessages got error: %v", err)
+		}
+	}
+}
+
+// handle runs the handler until it succeeds, backing off up to MaxBackoff between attempts, and
+// parks the message on the dead-letter topic only when the handler reports a permanent error: a
+// transient outage, however long, must not leave the event unhandled. It only returns an error when
+// ctx is done before the message was dealt with.
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message, handler MessageHandler) error {
+	var err error
+	backoff := c.cfg.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err = handler(ctx, msg.Value)
+		if err == nil {
+			return nil
+		}
+
+		fields := messageFields(msg)
+		fields["attempt"] = attempt
+		c.logger.WithFields(fields).Errorf("handler got error: %v", err)
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			break
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, c.cfg.MaxBackoff)
+	}
+
+	for {
+		dlqErr := c.publishDeadLetter(ctx, msg, err)
+		if dlqErr == nil {
+			return nil
+		}
+		c.logger.WithFields(messageFields(msg)).Errorf("c.publishDeadLetter got error: %v", dlqErr)
+		if err := sleep(ctx, c.cfg.MaxBackoff); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Consumer) publishDeadLetter(ctx context.Context, msg kafka.Message, handlerErr error) error {
+	deadLetter := kafka.Message{
+		Topic: msg.Topic + DeadLetterTopicSuffix,
+		Key:   msg.Key,
+		Value: msg.Value,
+		Headers: append(msg.Headers,
+			kafka.Header{Key: "dlq-original-topic", Value: []byte(msg.Topic)},
+			kafka.Header{Key: "dlq-original-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+			kafka.Header{Key: "dlq-original-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+			kafka.Header{Key: "dlq-consumer-group", Value: []byte(c.cfg.GroupID)},
+			kafka.Header{Key: "dlq-error", Value: []byte(handlerErr.Error())},
+		),
+	}
+	return c.deadLetters.WriteMessages(ctx, deadLetter)
+}
+
+// Close stops the readers and waits for in-flight messages. Cancel the ctx given to Start first.
+func (c *Consumer) Close() error {
+	var errs []error
+	for _, reader := range c.readers {
+		if err := reader.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.wg.Wait()
+	return errors.Join(errs...)
+}
+
+func messageFields(msg kafka.Message) logrus.Fields {
+	return logrus.Fields{
+		"topic":     msg.Topic,
+		"partition": msg.Partition,
+		"offset":    msg.Offset,
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
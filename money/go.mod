@@ -0,0 +1,3 @@
+module money
+
+go 1.24.5
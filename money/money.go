@@ -0,0 +1,217 @@
+// Package money represents amounts as integer minor units (cents) plus an ISO 4217 currency code,
+// so prices and totals never go through floating point.
+//
+// Adding amounts and multiplying them by a quantity is exact. Operations that produce a fraction of
+// a minor unit, like applying a rate, round half to even, so rounding errors don't drift in one
+// direction when many amounts are summed.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts stored before currencies were recorded.
+const DefaultCurrency = "USD"
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrInvalidRate      = errors.New("invalid exchange rate")
+)
+
+// minorUnits is the number of decimal places of the minor unit of every supported currency.
+var minorUnits = map[string]int{
+	"AUD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"IDR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"MYR": 2,
+	"SGD": 2,
+	"USD": 2,
+}
+
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func New(amount int64, currency string) Money {
+	return Money{
+		Amount:   amount,
+		Currency: strings.ToUpper(currency),
+	}
+}
+
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+func IsValidCurrency(currency string) bool {
+	_, ok := minorUnits[currency]
+	return ok
+}
+
+// MinorUnits returns the number of decimal places of currency.
+func MinorUnits(currency string) (int, error) {
+	units, ok := minorUnits[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return units, nil
+}
+
+// Validate checks that the currency is supported and the amount isn't negative.
+func (m Money) Validate() error {
+	if !IsValidCurrency(m.Currency) {
+		return fmt.Errorf("%w: %q", ErrUnknownCurrency, m.Currency)
+	}
+	if m.Amount < 0 {
+		return fmt.Errorf("negative amount %d", m.Amount)
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return New(m.Amount+other.Amount, m.Currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return New(m.Amount-other.Amount, m.Currency), nil
+}
+
+func (m Money) Mul(quantity int64) Money {
+	return New(m.Amount*quantity, m.Currency)
+}
+
+// MulRat multiplies the amount by numerator/denominator and rounds half to even.
+func (m Money) MulRat(numerator int64, denominator int64) Money {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(numerator))
+	return New(divRoundHalfEven(product, big.NewInt(denominator)), m.Currency)
+}
+
+// Convert converts m into currency at rate, the decimal price of one major unit of m's currency
+// in currency (e.g. "0.92" from USD to EUR), rounding half to even.
+func (m Money) Convert(currency string, rate string) (Money, error) {
+	r, err := ParseRate(rate)
+	if err != nil {
+		return Money{}, err
+	}
+	return m.convert(currency, r)
+}
+
+// ConvertInverse converts m into currency at rate, the decimal price of one major unit of
+// currency in m's currency, i.e. it undoes Convert.
+func (m Money) ConvertInverse(currency string, rate string) (Money, error) {
+	r, err := ParseRate(rate)
+	if err != nil {
+		return Money{}, err
+	}
+	return m.convert(currency, r.Inv(r))
+}
+
+// ParseRate parses a positive decimal exchange rate.
+func ParseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+	return r, nil
+}
+
+func (m Money) convert(currency string, rate *big.Rat) (Money, error) {
+	fromUnits, err := MinorUnits(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	toUnits, err := MinorUnits(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// amount in minor units of the source currency * rate * 10^toUnits / 10^fromUnits
+	numerator := new(big.Int).Mul(big.NewInt(m.Amount), rate.Num())
+	numerator.Mul(numerator, big.NewInt(pow10(toUnits)))
+	denominator := new(big.Int).Mul(rate.Denom(), big.NewInt(pow10(fromUnits)))
+	return New(divRoundHalfEven(numerator, denominator), currency), nil
+}
+
+func (m Money) Equal(other Money) bool {
+	return m.Amount == other.Amount && m.Currency == other.Currency
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// String formats the amount in major units, e.g. "12.50 USD".
+func (m Money) String() string {
+	units, ok := minorUnits[m.Currency]
+	if !ok || units == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	scale := pow10(units)
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/scale, units, amount%scale, m.Currency)
+}
+
+// Sum adds up amounts, which must all be in currency.
+func Sum(currency string, amounts ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, amount := range amounts {
+		var err error
+		total, err = total.Add(amount)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func divRoundHalfEven(numerator *big.Int, denominator *big.Int) int64 {
+	if denominator.Sign() < 0 {
+		numerator = new(big.Int).Neg(numerator)
+		denominator = new(big.Int).Neg(denominator)
+	}
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+
+	// compare twice the remainder with the denominator to find out which side of .5 we are on
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	switch twice.Cmp(denominator) {
+	case 1:
+		quotient.Add(quotient, big.NewInt(int64(numerator.Sign())))
+	case 0:
+		if quotient.Bit(0) == 1 {
+			quotient.Add(quotient, big.NewInt(int64(numerator.Sign())))
+		}
+	}
+	return quotient.Int64()
+}
+
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
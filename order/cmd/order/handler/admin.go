@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	constant "order/order/infrastructure/constans"
+	"order/order/infrastructure/log"
+	"order/order/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// orderExportHeader names the columns of the order CSV export. Amounts are in minor units of the
+// currency column.
+var orderExportHeader = []string{
+	"order_id", "user_id", "status", "create_time", "currency", "subtotal_amount", "discount_amount",
+	"tax_amount", "total_amount", "total_qty", "payment_method", "shipping_region", "items",
+}
+
+func (h *OrderHandler) SearchOrders(c *gin.Context) {
+	var req models.AdminOrderSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+
+	page, err := h.OrderUsecase.SearchOrders(c.Request.Context(), req)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"req": req,
+		}).Errorf("h.OrderUsecase.SearchOrders got error: %v", err)
+		writeOrderSearchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        page.Orders,
+		"next_cursor": page.NextCursor,
+	})
+}
+
+func (h *OrderHandler) ExportOrders(c *gin.Context) {
+	var req models.AdminOrderSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+
+	orders, err := h.OrderUsecase.ExportOrders(c.Request.Context(), req)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"req": req,
+		}).Errorf("h.OrderUsecase.ExportOrders got error: %v", err)
+		writeOrderSearchError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=orders-%s.csv", time.Now().UTC().Format("20060102T150405Z")))
+	c.Status(http.StatusOK)
+	writer := csv.NewWriter(c.Writer)
+	err = writer.Write(orderExportHeader)
+	for _, order := range orders {
+		if err != nil {
+			break
+		}
+		err = writer.Write(orderExportRow(order))
+	}
+	if err == nil {
+		writer.Flush()
+		err = writer.Error()
+	}
+	if err != nil {
+		// the status is already sent, all that is left is to log it
+		log.Logger.WithFields(logrus.Fields{
+			"req": req,
+		}).Errorf("csv.Writer.Write got error: %v", err)
+	}
+}
+
+func (h *OrderHandler) ForceOrderStatus(c *gin.Context) {
+	adminID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	var req models.ForceOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+	status, ok := constant.ParseOrderStatus(req.Status)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	param := models.ForceOrderStatusParam{
+		OrderID: orderID,
+		AdminID: adminID,
+		Status:  status,
+		Reason:  strings.TrimSpace(req.Reason),
+	}
+	order, err := h.OrderUsecase.ForceOrderStatus(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.OrderUsecase.ForceOrderStatus got error: %v", err)
+		switch {
+		case errors.Is(err, constant.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, constant.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully force order status",
+		"orderID": order.ID,
+		"status":  constant.OrderStatusTranslated[order.Status],
+	})
+}
+
+func writeOrderSearchError(c *gin.Context, err error) {
+	if errors.Is(err, constant.ErrInvalidHistoryQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// orderExportRow formats an order as a row of orderExportHeader. Items are listed as
+// product_id:quantity separated by spaces.
+func orderExportRow(order models.OrderHistoryResponse) []string {
+	items := make([]string, 0, len(order.Products))
+	for _, item := range order.Products {
+		items = append(items, fmt.Sprintf("%d:%d", item.ProductID, item.Quantity))
+	}
+	return []string{
+		strconv.FormatInt(order.OrderID, 10),
+		strconv.FormatInt(order.UserID, 10),
+		order.Status,
+		order.CreateTime.UTC().Format(time.RFC3339),
+		order.TotalAmount.Currency,
+		strconv.FormatInt(order.Subtotal.Amount, 10),
+		strconv.FormatInt(order.Discount.Amount, 10),
+		strconv.FormatInt(order.Tax.Amount, 10),
+		strconv.FormatInt(order.TotalAmount.Amount, 10),
+		strconv.Itoa(order.TotalQty),
+		csvText(order.PaymentMethod),
+		csvText(order.ShippingRegion),
+		strings.Join(items, " "),
+	}
+}
+
+// csvText keeps text entered by users from being run as a formula by spreadsheets opening the export.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	constant "order/order/infrastructure/constans"
+	"order/order/infrastructure/log"
+	"order/order/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *OrderHandler) GetCart(c *gin.Context) {
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.OrderUsecase.GetCart(c.Request.Context(), userID, c.Query("currency"))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+		}).Errorf("h.OrderUsecase.GetCart got error: %v", err)
+		writeCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully get cart",
+		"cart":    cart,
+	})
+}
+
+func (h *OrderHandler) AddCartItem(c *gin.Context) {
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+	var param models.AddCartItemRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+
+	err := h.OrderUsecase.AddCartItem(c.Request.Context(), userID, param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+			"param":  param,
+		}).Errorf("h.OrderUsecase.AddCartItem got error: %v", err)
+		writeCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully add item to cart",
+	})
+}
+
+func (h *OrderHandler) UpdateCartItem(c *gin.Context) {
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product id"})
+		return
+	}
+	var param models.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+
+	err = h.OrderUsecase.UpdateCartItem(c.Request.Context(), userID, productID, param.Quantity)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":    userID,
+			"productID": productID,
+			"param":     param,
+		}).Errorf("h.OrderUsecase.UpdateCartItem got error: %v", err)
+		writeCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully update cart item",
+	})
+}
+
+func (h *OrderHandler) RemoveCartItem(c *gin.Context) {
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	err = h.OrderUsecase.RemoveCartItem(c.Request.Context(), userID, productID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":    userID,
+			"productID": productID,
+		}).Errorf("h.OrderUsecase.RemoveCartItem got error: %v", err)
+		writeCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully remove cart item",
+	})
+}
+
+func (h *OrderHandler) ClearCart(c *gin.Context) {
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+
+	err := h.OrderUsecase.ClearCart(c.Request.Context(), userID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+		}).Errorf("h.OrderUsecase.ClearCart got error: %v", err)
+		writeCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully clear cart",
+	})
+}
+
+func (h *OrderHandler) CheckoutCart(c *gin.Context) {
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+	var param models.CheckoutCartRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+
+	orderID, err := h.OrderUsecase.CheckoutCart(c.Request.Context(), userID, c.GetHeader("Authorization"), param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+			"param":  param,
+		}).Errorf("h.OrderUsecase.CheckoutCart got error: %v", err)
+		writeCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Succesfull Create A Order",
+		"orderID": orderID,
+	})
+}
+
+func cartUserID(c *gin.Context) (int64, bool) {
+	userIDStr, isExist := c.Get("user_id")
+	if !isExist {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+	userID, ok := userIDStr.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user_id"})
+		return 0, false
+	}
+	return int64(userID), true
+}
+
+func writeCartError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, constant.ErrCartItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, constant.ErrEmptyCart), errors.Is(err, constant.ErrInvalidCheckout), errors.Is(err, constant.ErrProductNotFound), errors.Is(err, constant.ErrInvalidCoupon), errors.Is(err, constant.ErrAddressNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, constant.ErrInsufficientStock), errors.Is(err, constant.ErrCouponUsageLimitReached):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, constant.ErrProductServiceUnavailable), errors.Is(err, constant.ErrUserServiceUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
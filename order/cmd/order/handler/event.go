@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"order/order/infrastructure/log"
+	"order/order/kafka"
+	"order/order/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HandlePaymentSucceeded consumes the payment.succeeded topic.
+func (h *OrderHandler) HandlePaymentSucceeded(ctx context.Context, value []byte) error {
+	var event models.PaymentEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		// a malformed message will never succeed, park it on the dead-letter topic
+		return kafka.Permanent(err)
+	}
+
+	err := h.OrderUsecase.HandlePaymentSucceeded(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.OrderUsecase.HandlePaymentSucceeded got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HandlePaymentFailed consumes the payment.failed topic.
+func (h *OrderHandler) HandlePaymentFailed(ctx context.Context, value []byte) error {
+	var event models.PaymentEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return kafka.Permanent(err)
+	}
+
+	err := h.OrderUsecase.HandlePaymentFailed(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.OrderUsecase.HandlePaymentFailed got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HandlePaymentCancelled consumes the payment.cancelled topic.
+func (h *OrderHandler) HandlePaymentCancelled(ctx context.Context, value []byte) error {
+	var event models.PaymentEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return kafka.Permanent(err)
+	}
+
+	err := h.OrderUsecase.HandlePaymentCancelled(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.OrderUsecase.HandlePaymentCancelled got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HandleShipmentUpdated consumes the shipment.updated topic.
+func (h *OrderHandler) HandleShipmentUpdated(ctx context.Context, value []byte) error {
+	var event models.ShipmentEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return kafka.Permanent(err)
+	}
+
+	err := h.OrderUsecase.HandleShipmentUpdated(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.OrderUsecase.HandleShipmentUpdated got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HandlePaymentRefunded consumes the payment.refunded topic.
+func (h *OrderHandler) HandlePaymentRefunded(ctx context.Context, value []byte) error {
+	var event models.RefundEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return kafka.Permanent(err)
+	}
+
+	err := h.OrderUsecase.HandlePaymentRefunded(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.OrderUsecase.HandlePaymentRefunded got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HandlePaymentRefundFailed consumes the payment.refund_failed topic.
+func (h *OrderHandler) HandlePaymentRefundFailed(ctx context.Context, value []byte) error {
+	var event models.RefundEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return kafka.Permanent(err)
+	}
+
+	err := h.OrderUsecase.HandlePaymentRefundFailed(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.OrderUsecase.HandlePaymentRefundFailed got error: %v", err)
+		return err
+	}
+	return nil
+}
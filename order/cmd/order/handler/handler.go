@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"order/order/cmd/order/usecase"
+	constant "order/order/infrastructure/constans"
 	"order/order/infrastructure/log"
 	"order/order/models"
 	"strconv"
@@ -43,12 +45,22 @@ func (h *OrderHandler) CheckoutOrder(c *gin.Context) {
 	}
 
 	param.UserID = int64(userID)
+	param.Authorization = c.GetHeader("Authorization")
 	orderID, err := h.OrderUsecase.CheckOutOrder(c.Request.Context(), &param)
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
 			"param": param,
 		}).Errorf("h.OrderUsecase.CheckOutOrder got error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, constant.ErrInvalidCheckout), errors.Is(err, constant.ErrProductNotFound), errors.Is(err, constant.ErrInvalidCoupon), errors.Is(err, constant.ErrAddressNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, constant.ErrInsufficientStock), errors.Is(err, constant.ErrCouponUsageLimitReached):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, constant.ErrProductServiceUnavailable), errors.Is(err, constant.ErrUserServiceUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -69,23 +81,98 @@ func (h *OrderHandler) GetOrderHistory(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user_id"})
 		return
 	}
-	statusStr := c.DefaultQuery("status", "0")
-	status, _ := strconv.Atoi(statusStr)
-
-	param := models.OrderHistoryParam{
-		UserID: int64(userID),
-		Status: status,
+	var req models.OrderHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
 	}
-	orderHistory, err := h.OrderUsecase.GetOrderHistoryByUserID(c.Request.Context(), &param)
+
+	page, err := h.OrderUsecase.GetOrderHistoryByUserID(c.Request.Context(), int64(userID), req)
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
-			"param": param,
+			"userID": userID,
+			"req":    req,
 		}).Errorf("h.OrderUsecase.GetOrderHistoryByUserID got error: %v", err)
+		if errors.Is(err, constant.ErrInvalidHistoryQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        page.Orders,
+		"next_cursor": page.NextCursor,
+	})
+
+}
+
+func (h *OrderHandler) GetOrderDetail(c *gin.Context) {
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	isAdmin := c.GetString("role") == constant.RoleAdmin
+	order, err := h.OrderUsecase.GetOrderDetail(c.Request.Context(), orderID, userID, isAdmin)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"orderID": orderID,
+			"userID":  userID,
+		}).Errorf("h.OrderUsecase.GetOrderDetail got error: %v", err)
+		if errors.Is(err, constant.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"data": orderHistory,
+		"data": order,
 	})
+}
+
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	userIDStr, isExist := c.Get("user_id")
+	if !isExist {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID, ok := userIDStr.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user_id"})
+		return
+	}
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
 
+	order, err := h.OrderUsecase.CancelOrder(c.Request.Context(), orderID, int64(userID))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"orderID": orderID,
+			"userID":  userID,
+		}).Errorf("h.OrderUsecase.CancelOrder got error: %v", err)
+		switch {
+		case errors.Is(err, constant.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, constant.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": "order can no longer be cancelled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully cancel order",
+		"orderID": order.ID,
+		"status":  constant.OrderStatusTranslated[order.Status],
+	})
 }
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"order/order/cmd/order/worker"
+	"order/order/infrastructure/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxHandler struct {
+	OutboxRelay *worker.OutboxRelay
+}
+
+func NewOutboxHandler(outboxRelay *worker.OutboxRelay) *OutboxHandler {
+	return &OutboxHandler{
+		OutboxRelay: outboxRelay,
+	}
+}
+
+func (h *OutboxHandler) GetOutboxMetrics(c *gin.Context) {
+	metrics, err := h.OutboxRelay.Metrics(c.Request.Context())
+	if err != nil {
+		log.Logger.Errorf("h.OutboxRelay.Metrics got error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": metrics,
+	})
+}
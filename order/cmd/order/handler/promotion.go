@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	constant "order/order/infrastructure/constans"
+	"order/order/infrastructure/log"
+	"order/order/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *OrderHandler) GetPromotions(c *gin.Context) {
+	promotions, err := h.OrderUsecase.GetPromotions(c.Request.Context())
+	if err != nil {
+		log.Logger.Errorf("h.OrderUsecase.GetPromotions got error: %v", err)
+		writePromotionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": promotions,
+	})
+}
+
+func (h *OrderHandler) GetPromotion(c *gin.Context) {
+	promotionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+		return
+	}
+
+	promotion, err := h.OrderUsecase.GetPromotion(c.Request.Context(), promotionID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"promotionID": promotionID,
+		}).Errorf("h.OrderUsecase.GetPromotion got error: %v", err)
+		writePromotionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": promotion,
+	})
+}
+
+func (h *OrderHandler) CreatePromotion(c *gin.Context) {
+	var req models.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+
+	promotion, err := h.OrderUsecase.CreatePromotion(c.Request.Context(), req)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"req": req,
+		}).Errorf("h.OrderUsecase.CreatePromotion got error: %v", err)
+		writePromotionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Successfully create promotion",
+		"promotion": promotion,
+	})
+}
+
+func (h *OrderHandler) UpdatePromotion(c *gin.Context) {
+	promotionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+		return
+	}
+	var req models.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+
+	promotion, err := h.OrderUsecase.UpdatePromotion(c.Request.Context(), promotionID, req)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"promotionID": promotionID,
+			"req":         req,
+		}).Errorf("h.OrderUsecase.UpdatePromotion got error: %v", err)
+		writePromotionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Successfully update promotion",
+		"promotion": promotion,
+	})
+}
+
+func (h *OrderHandler) DeactivatePromotion(c *gin.Context) {
+	promotionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+		return
+	}
+
+	promotion, err := h.OrderUsecase.DeactivatePromotion(c.Request.Context(), promotionID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"promotionID": promotionID,
+		}).Errorf("h.OrderUsecase.DeactivatePromotion got error: %v", err)
+		writePromotionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Successfully deactivate promotion",
+		"promotion": promotion,
+	})
+}
+
+func writePromotionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, constant.ErrPromotionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, constant.ErrInvalidPromotion):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	constant "order/order/infrastructure/constans"
+	"order/order/infrastructure/log"
+	"order/order/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *OrderHandler) CreateReturn(c *gin.Context) {
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	var param models.CreateReturnRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+
+	orderReturn, err := h.OrderUsecase.CreateReturn(c.Request.Context(), userID, orderID, param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":  userID,
+			"orderID": orderID,
+			"param":   param,
+		}).Errorf("h.OrderUsecase.CreateReturn got error: %v", err)
+		writeReturnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully request return",
+		"return":  orderReturn,
+	})
+}
+
+func (h *OrderHandler) GetOrderReturns(c *gin.Context) {
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	orderReturns, err := h.OrderUsecase.GetOrderReturns(c.Request.Context(), userID, orderID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":  userID,
+			"orderID": orderID,
+		}).Errorf("h.OrderUsecase.GetOrderReturns got error: %v", err)
+		writeReturnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": orderReturns,
+	})
+}
+
+func (h *OrderHandler) GetReturns(c *gin.Context) {
+	status := c.Query("status")
+	orderReturns, err := h.OrderUsecase.GetReturns(c.Request.Context(), status)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"status": status,
+		}).Errorf("h.OrderUsecase.GetReturns got error: %v", err)
+		writeReturnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": orderReturns,
+	})
+}
+
+func (h *OrderHandler) ApproveReturn(c *gin.Context) {
+	returnID, param, ok := reviewReturnParam(c)
+	if !ok {
+		return
+	}
+
+	orderReturn, err := h.OrderUsecase.ApproveReturn(c.Request.Context(), returnID, param.Note)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"returnID": returnID,
+			"param":    param,
+		}).Errorf("h.OrderUsecase.ApproveReturn got error: %v", err)
+		writeReturnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully approve return",
+		"return":  orderReturn,
+	})
+}
+
+func (h *OrderHandler) RejectReturn(c *gin.Context) {
+	returnID, param, ok := reviewReturnParam(c)
+	if !ok {
+		return
+	}
+
+	orderReturn, err := h.OrderUsecase.RejectReturn(c.Request.Context(), returnID, param.Note)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"returnID": returnID,
+			"param":    param,
+		}).Errorf("h.OrderUsecase.RejectReturn got error: %v", err)
+		writeReturnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully reject return",
+		"return":  orderReturn,
+	})
+}
+
+func (h *OrderHandler) ReceiveReturn(c *gin.Context) {
+	returnID, ok := returnIDParam(c)
+	if !ok {
+		return
+	}
+	var param models.ReceiveReturnRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+		return
+	}
+
+	orderReturn, err := h.OrderUsecase.ReceiveReturn(c.Request.Context(), returnID, c.GetHeader("Authorization"), param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"returnID": returnID,
+			"param":    param,
+		}).Errorf("h.OrderUsecase.ReceiveReturn got error: %v", err)
+		writeReturnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully receive return",
+		"return":  orderReturn,
+	})
+}
+
+func (h *OrderHandler) RetryRefund(c *gin.Context) {
+	returnID, ok := returnIDParam(c)
+	if !ok {
+		return
+	}
+
+	orderReturn, err := h.OrderUsecase.RetryRefund(c.Request.Context(), returnID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"returnID": returnID,
+		}).Errorf("h.OrderUsecase.RetryRefund got error: %v", err)
+		writeReturnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully request refund",
+		"return":  orderReturn,
+	})
+}
+
+func returnIDParam(c *gin.Context) (int64, bool) {
+	returnID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid return id"})
+		return 0, false
+	}
+	return returnID, true
+}
+
+func reviewReturnParam(c *gin.Context) (int64, models.ReviewReturnRequest, bool) {
+	var param models.ReviewReturnRequest
+	returnID, ok := returnIDParam(c)
+	if !ok {
+		return 0, param, false
+	}
+	// the note is optional, so is the body
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&param); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "error_detail": err.Error()})
+			return 0, param, false
+		}
+	}
+	return returnID, param, true
+}
+
+func writeReturnError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, constant.ErrOrderNotFound), errors.Is(err, constant.ErrReturnNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, constant.ErrInvalidReturn):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, constant.ErrInvalidReturnTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, constant.ErrProductServiceUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
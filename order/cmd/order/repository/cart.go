@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"order/order/models"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+var (
+	cacheKeyCart = "cart:%d"
+	cartCacheTTL = 7 * 24 * time.Hour
+)
+
+// GetCartItemsFromRedis reads a cached cart. A cart that isn't cached comes back empty.
+func (r *OrderRepository) GetCartItemsFromRedis(ctx context.Context, userID int64) ([]models.CartItem, error) {
+	values, err := r.Redis.HGetAll(ctx, fmt.Sprintf(cacheKeyCart, userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.CartItem, 0, len(values))
+	for _, value := range values {
+		var item models.CartItem
+		err = json.Unmarshal([]byte(value), &item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+// SetCartItems replaces the cached cart with items, keyed by product ID.
+func (r *OrderRepository) SetCartItems(ctx context.Context, userID int64, items []models.CartItem) error {
+	cacheKey := fmt.Sprintf(cacheKeyCart, userID)
+	fields := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		itemJSON, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		fields[strconv.FormatInt(item.ProductID, 10)] = itemJSON
+	}
+
+	pipe := r.Redis.TxPipeline()
+	pipe.Del(ctx, cacheKey)
+	if len(fields) > 0 {
+		pipe.HSet(ctx, cacheKey, fields)
+		pipe.Expire(ctx, cacheKey, cartCacheTTL)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *OrderRepository) DeleteCartCache(ctx context.Context, userID int64) error {
+	return r.Redis.Del(ctx, fmt.Sprintf(cacheKeyCart, userID)).Err()
+}
+
+func (r *OrderRepository) FindCartItemsByUserID(ctx context.Context, userID int64) ([]models.CartItem, error) {
+	var items []models.CartItem
+	err := r.Database.WithContext(ctx).Table("cart_item").Where("user_id = ?", userID).Order("id").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// UpsertCartItem sets the quantity of a product in the cart, adding the product if needed.
+func (r *OrderRepository) UpsertCartItem(ctx context.Context, userID int64, productID int64, quantity int) error {
+	now := time.Now()
+	item := models.CartItem{
+		UserID:     userID,
+		ProductID:  productID,
+		Quantity:   quantity,
+		CreateTime: now,
+		UpdateTime: now,
+	}
+	err := r.Database.WithContext(ctx).Table("cart_item").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "update_time"}),
+	}).Create(&item).Error
+	return err
+}
+
+// DeleteCartItems removes the given products from the cart, or the whole cart when productIDs is
+// empty. It returns the number of removed items.
+func (r *OrderRepository) DeleteCartItems(ctx context.Context, userID int64, productIDs []int64) (int64, error) {
+	query := r.Database.WithContext(ctx).Table("cart_item").Where("user_id = ?", userID)
+	if len(productIDs) > 0 {
+		query = query.Where("product_id IN ?", productIDs)
+	}
+	result := query.Delete(&models.CartItem{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
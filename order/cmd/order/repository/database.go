@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (r *OrderRepository) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
@@ -40,71 +41,330 @@ func (r *OrderRepository) InsertOrderDetailTx(ctx context.Context, tx *gorm.DB,
 	return err
 }
 
-func (r *OrderRepository) CheckIdempotency(ctx context.Context, token string) (bool, error) {
+func (r *OrderRepository) GetOrderByIDForUpdateTx(ctx context.Context, tx *gorm.DB, orderID int64) (*models.Order, error) {
+	var order models.Order
+	err := tx.WithContext(ctx).Table("orders").Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderID).First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *OrderRepository) UpdateOrderStatusTx(ctx context.Context, tx *gorm.DB, orderID int64, status int) error {
+	err := tx.WithContext(ctx).Table("orders").Where("id = ?", orderID).Updates(map[string]interface{}{
+		"status":      status,
+		"update_time": time.Now(),
+	}).Error
+	return err
+}
+
+func (r *OrderRepository) InsertOrderItemsTx(ctx context.Context, tx *gorm.DB, items []models.OrderItem) error {
+	err := tx.WithContext(ctx).Table("order_item").Create(&items).Error
+	return err
+}
+
+func (r *OrderRepository) AppendOrderHistoryTx(ctx context.Context, tx *gorm.DB, orderID int64, entry *models.OrderStatusHistory) error {
+	entry.OrderID = orderID
+	err := tx.WithContext(ctx).Table("order_status_history").Create(entry).Error
+	return err
+}
+
+func (r *OrderRepository) FindOrderItemsByOrderIDTx(ctx context.Context, tx *gorm.DB, orderID int64) ([]models.CheckOutItem, error) {
+	var orderItems []models.OrderItem
+	err := tx.WithContext(ctx).Table("order_item").Where("order_id = ?", orderID).Order("line_no").Find(&orderItems).Error
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.CheckOutItem, 0, len(orderItems))
+	for _, orderItem := range orderItems {
+		item, err := orderItem.CheckOutItem()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// FindOrderItemsByOrderIDs returns the items of the orders, keyed by order ID.
+func (r *OrderRepository) FindOrderItemsByOrderIDs(ctx context.Context, orderIDs []int64) (map[int64][]models.CheckOutItem, error) {
+	var orderItems []models.OrderItem
+	err := r.Database.WithContext(ctx).Table("order_item").Where("order_id IN ?", orderIDs).Order("order_id, line_no").Find(&orderItems).Error
+	if err != nil {
+		return nil, err
+	}
+	itemsByOrderID := make(map[int64][]models.CheckOutItem, len(orderIDs))
+	for _, orderItem := range orderItems {
+		item, err := orderItem.CheckOutItem()
+		if err != nil {
+			return nil, err
+		}
+		itemsByOrderID[orderItem.OrderID] = append(itemsByOrderID[orderItem.OrderID], item)
+	}
+	return itemsByOrderID, nil
+}
+
+// FindOrderHistoryByOrderIDs returns the status timeline of the orders, keyed by order ID.
+func (r *OrderRepository) FindOrderHistoryByOrderIDs(ctx context.Context, orderIDs []int64) (map[int64][]models.Statushistory, error) {
+	var entries []models.OrderStatusHistory
+	err := r.Database.WithContext(ctx).Table("order_status_history").Where("order_id IN ?", orderIDs).Order("order_id, id").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	historyByOrderID := make(map[int64][]models.Statushistory, len(orderIDs))
+	for _, entry := range entries {
+		historyByOrderID[entry.OrderID] = append(historyByOrderID[entry.OrderID], entry.Statushistory())
+	}
+	return historyByOrderID, nil
+}
+
+// UpsertOrderPaymentTx records the payment of an order. A payment already cancelled or refunded is
+// final and left as it is, payment events of different topics may arrive out of order.
+func (r *OrderRepository) UpsertOrderPaymentTx(ctx context.Context, tx *gorm.DB, payment *models.OrderPayment) error {
+	err := tx.WithContext(ctx).Table("order_payment").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "order_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"payment_id", "status", "provider", "provider_ref", "failure_reason", "update_time"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "order_payment.status NOT IN (?, ?)", Vars: []interface{}{constant.PaymentStatusCancelled, constant.PaymentStatusRefunded}},
+		}},
+	}).Create(payment).Error
+	return err
+}
+
+// FindIdempotencyLog returns the unexpired request log of a user's idempotency token, or nil.
+func (r *OrderRepository) FindIdempotencyLog(ctx context.Context, userID int64, token string) (*models.OrderRequestLog, error) {
 	var log models.OrderRequestLog
-	err := r.Database.WithContext(ctx).Table("order_request_log").First(&log, "idempotency_token = ?", token).Error
+	err := r.Database.WithContext(ctx).Table("order_request_log").
+		Where("user_id = ? AND idempotency_token = ? AND expire_time > ?", userID, token, time.Now()).
+		First(&log).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, nil
+			return nil, nil
 		}
-		return false, err
+		return nil, err
 	}
-	return true, nil
+	return &log, nil
 }
 
-func (r *OrderRepository) SaveIdempotency(ctx context.Context, token string) error {
-	log := models.OrderRequestLog{
-		IdempotencyToken: token,
-		CreateTime:       time.Now(),
+// InsertIdempotencyLogTx claims an idempotency token for an order. An expired claim on the same
+// token is dropped first; a live one makes the insert fail with constant.ErrDuplicateIdempotency.
+func (r *OrderRepository) InsertIdempotencyLogTx(ctx context.Context, tx *gorm.DB, log *models.OrderRequestLog) error {
+	err := tx.WithContext(ctx).Table("order_request_log").
+		Where("user_id = ? AND idempotency_token = ? AND expire_time <= ?", log.UserID, log.IdempotencyToken, time.Now()).
+		Delete(&models.OrderRequestLog{}).Error
+	if err != nil {
+		return err
 	}
-	err := r.Database.WithContext(ctx).Table("order_request_log").Create(&log).Error
+
+	err = tx.WithContext(ctx).Table("order_request_log").Create(log).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return constant.ErrDuplicateIdempotency
+		}
 		return err
 	}
 	return nil
-
 }
 
-func (r *OrderRepository) GetOrderHistoryByUserID(ctx context.Context, param models.OrderHistoryParam) ([]models.OrderHistoryResponse, error) {
+// SearchOrderHistory returns up to param.Limit orders after param.Cursor, in the param.Sort order,
+// of param.UserID or of all users when it is zero. The cursor is compared on the sort key and the
+// order ID, so a page never skips or repeats orders that share the same amount.
+func (r *OrderRepository) SearchOrderHistory(ctx context.Context, param models.OrderHistoryParam) ([]models.OrderHistoryResponse, error) {
 	var queryResults []models.OrderHistoryResult
 
-	query := r.Database.WithContext(ctx).Table("orders AS o").
-		Select("o.id , o.amount, o.total_qty, o.status, o.payment_method, o.shipping_address, od.products, od.order_history").
-		Joins("join order_detail AS od ON o.order_detail_id = od.id").Where("o.user_id = ?", param.UserID).
-		Where("o.user_id = ?", param.UserID)
+	query := r.orderHistoryQuery(ctx)
 
-	if param.Status > 0 {
-		query = query.Where("o.status = ?", param.Status)
+	if param.UserID != 0 {
+		query = query.Where("o.user_id = ?", param.UserID)
+	}
+	if len(param.Statuses) > 0 {
+		query = query.Where("o.status IN ?", param.Statuses)
+	}
+	if param.From != nil {
+		query = query.Where("o.create_time >= ?", *param.From)
+	}
+	if param.To != nil {
+		query = query.Where("o.create_time < ?", *param.To)
+	}
+	if param.MinAmount != nil {
+		query = query.Where("o.amount >= ?", *param.MinAmount)
+	}
+	if param.MaxAmount != nil {
+		query = query.Where("o.amount <= ?", *param.MaxAmount)
+	}
+	if param.Currency != "" {
+		query = query.Where("o.currency = ?", param.Currency)
+	}
+	if param.ProductID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM order_item AS oi WHERE oi.order_id = o.id AND oi.product_id = ?)", param.ProductID)
+	}
+
+	cursor := param.Cursor
+	switch param.Sort {
+	case constant.OrderHistorySortOldest:
+		if cursor != nil {
+			query = query.Where("o.id > ?", cursor.ID)
+		}
+		query = query.Order("o.id ASC")
+	case constant.OrderHistorySortAmountDesc:
+		if cursor != nil {
+			query = query.Where("(o.amount, o.id) < (?, ?)", cursor.Amount, cursor.ID)
+		}
+		query = query.Order("o.amount DESC, o.id DESC")
+	case constant.OrderHistorySortAmountAsc:
+		if cursor != nil {
+			query = query.Where("(o.amount, o.id) > (?, ?)", cursor.Amount, cursor.ID)
+		}
+		query = query.Order("o.amount ASC, o.id ASC")
+	default:
+		if cursor != nil {
+			query = query.Where("o.id < ?", cursor.ID)
+		}
+		query = query.Order("o.id DESC")
 	}
-	err := query.Order("o.id DESC").Find(&queryResults).Error
+
+	err := query.Limit(param.Limit).Find(&queryResults).Error
 	if err != nil {
 		return nil, err
 	}
-	var results []models.OrderHistoryResponse
+	results := make([]models.OrderHistoryResponse, 0, len(queryResults))
 
 	for _, result := range queryResults {
-		var products []models.CheckOutItem
-		var orderHistory []models.Statushistory
-
-		err = json.Unmarshal([]byte(result.Products), &products)
+		response, err := toOrderHistoryResponse(result)
 		if err != nil {
 			return nil, err
 		}
-		err = json.Unmarshal([]byte(result.OrderHistory), &orderHistory)
+		results = append(results, response)
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	err = r.attachOrderLines(ctx, results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetOrderByID returns an order with its products, history and shipments.
+func (r *OrderRepository) GetOrderByID(ctx context.Context, orderID int64) (*models.OrderHistoryResponse, error) {
+	var queryResult models.OrderHistoryResult
+	err := r.orderHistoryQuery(ctx).Where("o.id = ?", orderID).First(&queryResult).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrOrderNotFound
+		}
+		return nil, err
+	}
+	response, err := toOrderHistoryResponse(queryResult)
+	if err != nil {
+		return nil, err
+	}
+	results := []models.OrderHistoryResponse{response}
+	err = r.attachOrderLines(ctx, results)
+	if err != nil {
+		return nil, err
+	}
+	return &results[0], nil
+}
+
+func (r *OrderRepository) FindOrderPaymentByOrderID(ctx context.Context, orderID int64) (*models.OrderPayment, error) {
+	return r.FindOrderPaymentByOrderIDTx(ctx, r.Database, orderID)
+}
+
+func (r *OrderRepository) FindOrderPaymentByOrderIDTx(ctx context.Context, tx *gorm.DB, orderID int64) (*models.OrderPayment, error) {
+	var payment models.OrderPayment
+	err := tx.WithContext(ctx).Table("order_payment").Where("order_id = ?", orderID).First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *OrderRepository) orderHistoryQuery(ctx context.Context) *gorm.DB {
+	return r.Database.WithContext(ctx).Table("orders AS o").
+		Select("o.id , o.user_id, o.subtotal_amount, o.subtotal_currency, o.discount_amount, o.discount_currency, o.tax_amount, o.tax_currency, o.tax_mode, o.amount, o.currency, o.exchange_rate, o.total_qty, o.status, o.payment_method, o.shipping_address, o.shipping_region, o.shipping_address_detail, o.create_time, od.discounts, od.taxes").
+		Joins("join order_detail AS od ON o.order_detail_id = od.id")
+}
+
+func toOrderHistoryResponse(result models.OrderHistoryResult) (models.OrderHistoryResponse, error) {
+	var promotions []models.AppliedPromotion
+	var taxes []models.TaxBreakdown
+
+	err := json.Unmarshal([]byte(result.Discounts), &promotions)
+	if err != nil {
+		return models.OrderHistoryResponse{}, err
+	}
+	err = json.Unmarshal([]byte(result.Taxes), &taxes)
+	if err != nil {
+		return models.OrderHistoryResponse{}, err
+	}
+	var shippingAddress *models.Address
+	if result.ShippingAddressDetail != "" {
+		err = json.Unmarshal([]byte(result.ShippingAddressDetail), &shippingAddress)
 		if err != nil {
-			return nil, err
+			return models.OrderHistoryResponse{}, err
 		}
-		results = append(results, models.OrderHistoryResponse{
-			OrderID:         result.ID,
-			TotalAmount:     result.Amount,
-			TotalQty:        result.TotalQty,
-			Status:          constant.OrderStatusTranslated[result.Status],
-			PaymentMethod:   result.PaymentMethod,
-			ShippingAddress: result.ShippingAddress,
-			Products:        products,
-			History:         orderHistory,
-		})
 	}
+	return models.OrderHistoryResponse{
+		OrderID:               result.ID,
+		UserID:                result.UserID,
+		Subtotal:              result.Subtotal,
+		Discount:              result.Discount,
+		Promotions:            promotions,
+		Tax:                   result.Tax,
+		TaxMode:               result.TaxMode,
+		Taxes:                 taxes,
+		TotalAmount:           result.Amount,
+		ExchangeRate:          result.ExchangeRate,
+		TotalQty:              result.TotalQty,
+		Status:                constant.OrderStatusTranslated[result.Status],
+		PaymentMethod:         result.PaymentMethod,
+		ShippingAddress:       result.ShippingAddress,
+		ShippingRegion:        result.ShippingRegion,
+		ShippingAddressDetail: shippingAddress,
+		CreateTime:            result.CreateTime,
+	}, nil
+}
 
-	return results, nil
+// attachOrderLines loads the items, status timeline and shipments of all results, one query each.
+func (r *OrderRepository) attachOrderLines(ctx context.Context, results []models.OrderHistoryResponse) error {
+	orderIDs := make([]int64, 0, len(results))
+	for _, result := range results {
+		orderIDs = append(orderIDs, result.OrderID)
+	}
+	items, err := r.FindOrderItemsByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		return err
+	}
+	history, err := r.FindOrderHistoryByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		return err
+	}
+	shipments, err := r.FindOrderShipmentsByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		results[i].Products = items[results[i].OrderID]
+		if results[i].Products == nil {
+			results[i].Products = []models.CheckOutItem{}
+		}
+		results[i].History = history[results[i].OrderID]
+		if results[i].History == nil {
+			results[i].History = []models.Statushistory{}
+		}
+		results[i].Shipments = shipments[results[i].OrderID]
+		if results[i].Shipments == nil {
+			results[i].Shipments = []models.OrderShipment{}
+		}
+	}
+	return nil
 }
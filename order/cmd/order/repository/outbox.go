@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *OrderRepository) InsertOutboxTx(ctx context.Context, tx *gorm.DB, outbox *models.OrderOutbox) error {
+	err := tx.WithContext(ctx).Table("order_outbox").Omit("SentTime").Create(outbox).Error
+	return err
+}
+
+// FetchPendingOutboxTx locks up to limit messages that are due for delivery. Rows locked by another
+// relay are skipped, and a message is held back while an older message of the same order is still
+// pending so events of one order are always published in order.
+func (r *OrderRepository) FetchPendingOutboxTx(ctx context.Context, tx *gorm.DB, limit int) ([]models.OrderOutbox, error) {
+	var messages []models.OrderOutbox
+	err := tx.WithContext(ctx).Table("order_outbox AS o").
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("o.status = ? AND o.next_attempt_time <= ?", constant.OutboxStatusPending, time.Now()).
+		Where("NOT EXISTS (SELECT 1 FROM order_outbox p WHERE p.aggregate_id = o.aggregate_id AND p.status = ? AND p.id < o.id)", constant.OutboxStatusPending).
+		Order("o.id").Limit(limit).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *OrderRepository) MarkOutboxSentTx(ctx context.Context, tx *gorm.DB, id int64) error {
+	err := tx.WithContext(ctx).Table("order_outbox").Where("id = ?", id).Updates(map[string]interface{}{
+		"status":    constant.OutboxStatusSent,
+		"attempts":  gorm.Expr("attempts + 1"),
+		"sent_time": time.Now(),
+	}).Error
+	return err
+}
+
+func (r *OrderRepository) MarkOutboxRetryTx(ctx context.Context, tx *gorm.DB, outbox *models.OrderOutbox) error {
+	err := tx.WithContext(ctx).Table("order_outbox").Where("id = ?", outbox.ID).Updates(map[string]interface{}{
+		"status":            outbox.Status,
+		"attempts":          outbox.Attempts,
+		"last_error":        outbox.LastError,
+		"next_attempt_time": outbox.NextAttemptTime,
+	}).Error
+	return err
+}
+
+func (r *OrderRepository) GetOutboxStats(ctx context.Context) (models.OutboxStats, error) {
+	var result struct {
+		Pending       int64
+		Failed        int64
+		OldestPending *time.Time
+	}
+	err := r.Database.WithContext(ctx).Table("order_outbox").
+		Select("COUNT(*) FILTER (WHERE status = ?) AS pending, COUNT(*) FILTER (WHERE status = ?) AS failed, MIN(create_time) FILTER (WHERE status = ?) AS oldest_pending",
+			constant.OutboxStatusPending, constant.OutboxStatusFailed, constant.OutboxStatusPending).
+		Scan(&result).Error
+	if err != nil {
+		return models.OutboxStats{}, err
+	}
+
+	stats := models.OutboxStats{
+		Pending: result.Pending,
+		Failed:  result.Failed,
+	}
+	if result.OldestPending != nil {
+		stats.OldestPendingAge = time.Since(*result.OldestPending)
+	}
+	return stats, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FindPromotionsByCodes returns the promotions of the given upper case codes; codes are matched
+// case insensitively.
+func (r *OrderRepository) FindPromotionsByCodes(ctx context.Context, codes []string) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := r.Database.WithContext(ctx).Table("promotion").Where("upper(code) IN ?", codes).Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// FindPromotions returns up to limit promotions, the newest first.
+func (r *OrderRepository) FindPromotions(ctx context.Context, limit int) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := r.Database.WithContext(ctx).Table("promotion").Order("id DESC").Limit(limit).Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (r *OrderRepository) FindPromotionByID(ctx context.Context, promotionID int64) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := r.Database.WithContext(ctx).Table("promotion").Where("id = ?", promotionID).First(&promotion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrPromotionNotFound
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *OrderRepository) InsertPromotion(ctx context.Context, promotion *models.Promotion) error {
+	err := r.Database.WithContext(ctx).Table("promotion").Create(promotion).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: code %s is already used", constant.ErrInvalidPromotion, promotion.Code)
+	}
+	return err
+}
+
+// UpdatePromotion stores everything about a promotion but its usage count, which only checkouts
+// change.
+func (r *OrderRepository) UpdatePromotion(ctx context.Context, promotion *models.Promotion) error {
+	result := r.Database.WithContext(ctx).Table("promotion").Where("id = ?", promotion.ID).Updates(map[string]interface{}{
+		"code":                 promotion.Code,
+		"name":                 promotion.Name,
+		"type":                 promotion.Type,
+		"discount_bps":         promotion.DiscountBps,
+		"discount_amount":      promotion.DiscountAmount,
+		"currency":             promotion.Currency,
+		"category_id":          promotion.CategoryID,
+		"buy_qty":              promotion.BuyQty,
+		"get_qty":              promotion.GetQty,
+		"min_spend_amount":     promotion.MinSpendAmount,
+		"usage_limit":          promotion.UsageLimit,
+		"usage_limit_per_user": promotion.UsageLimitPerUser,
+		"stackable":            promotion.Stackable,
+		"active":               promotion.Active,
+		"start_time":           promotion.StartTime,
+		"end_time":             promotion.EndTime,
+		"update_time":          gorm.Expr("current_timestamp"),
+	})
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: code %s is already used", constant.ErrInvalidPromotion, promotion.Code)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrPromotionNotFound
+	}
+	return nil
+}
+
+func (r *OrderRepository) GetPromotionByIDForUpdateTx(ctx context.Context, tx *gorm.DB, promotionID int64) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := tx.WithContext(ctx).Table("promotion").Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", promotionID).First(&promotion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrInvalidCoupon
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *OrderRepository) CountPromotionUsagesByUserTx(ctx context.Context, tx *gorm.DB, promotionID int64, userID int64) (int64, error) {
+	var count int64
+	err := tx.WithContext(ctx).Table("promotion_usage").Where("promotion_id = ? AND user_id = ?", promotionID, userID).Count(&count).Error
+	return count, err
+}
+
+func (r *OrderRepository) IncrementPromotionUsageTx(ctx context.Context, tx *gorm.DB, promotionID int64) error {
+	err := tx.WithContext(ctx).Table("promotion").Where("id = ?", promotionID).Updates(map[string]interface{}{
+		"usage_count": gorm.Expr("usage_count + 1"),
+		"update_time": gorm.Expr("current_timestamp"),
+	}).Error
+	return err
+}
+
+func (r *OrderRepository) InsertPromotionUsageTx(ctx context.Context, tx *gorm.DB, usage *models.PromotionUsage) error {
+	err := tx.WithContext(ctx).Table("promotion_usage").Create(usage).Error
+	return err
+}
+
+func (r *OrderRepository) DecrementPromotionUsageTx(ctx context.Context, tx *gorm.DB, promotionID int64) error {
+	err := tx.WithContext(ctx).Table("promotion").Where("id = ?", promotionID).Updates(map[string]interface{}{
+		"usage_count": gorm.Expr("greatest(usage_count - 1, 0)"),
+		"update_time": gorm.Expr("current_timestamp"),
+	}).Error
+	return err
+}
+
+// DeletePromotionUsagesByOrderIDTx deletes the redemptions of an order and returns them.
+func (r *OrderRepository) DeletePromotionUsagesByOrderIDTx(ctx context.Context, tx *gorm.DB, orderID int64) ([]models.PromotionUsage, error) {
+	var usages []models.PromotionUsage
+	err := tx.WithContext(ctx).Table("promotion_usage").Clauses(clause.Returning{}).Where("order_id = ?", orderID).Delete(&usages).Error
+	if err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
@@ -2,10 +2,8 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"order/order/infrastructure/log"
+	"order/order/infrastructure/productclient"
+	"order/order/infrastructure/userclient"
 	"order/order/models"
 
 	"github.com/redis/go-redis/v9"
@@ -13,48 +11,66 @@ import (
 )
 
 type OrderRepository struct {
-	Database    *gorm.DB
-	Redis       *redis.Client
-	ProductHost string
+	Database      *gorm.DB
+	Redis         *redis.Client
+	ProductClient *productclient.Client
+	UserClient    *userclient.Client
 }
 
-func NewOrderRepository(db *gorm.DB, rdb *redis.Client, productHost string) *OrderRepository {
+func NewOrderRepository(db *gorm.DB, rdb *redis.Client, productClient *productclient.Client, userClient *userclient.Client) *OrderRepository {
 	return &OrderRepository{
-		Database:    db,
-		Redis:       rdb,
-		ProductHost: productHost,
+		Database:      db,
+		Redis:         rdb,
+		ProductClient: productClient,
+		UserClient:    userClient,
 	}
 }
 
-func (r *OrderRepository) GetProductInfo(ctx context.Context, productID int64) (models.Product, error) {
-	var product models.Product
-
-	url := fmt.Sprintf("%s/v1/product/%d", r.ProductHost, productID)
-	log.Logger.Info("MicroService: Fetching product info from URL: ", url)
+func (r *OrderRepository) GetProductsInfo(ctx context.Context, productIDs []int64, currency string) (map[int64]models.Product, error) {
+	return r.ProductClient.GetProducts(ctx, productIDs, currency)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return models.Product{}, err
+func (r *OrderRepository) ReserveStock(ctx context.Context, reservationID string, items []models.CheckOutItem) error {
+	param := models.ReserveStockRequest{
+		ReservationID: reservationID,
 	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return models.Product{}, err
+	for _, item := range items {
+		param.Items = append(param.Items, models.StockReservationItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
 	}
+	return r.ProductClient.ReserveStock(ctx, param)
+}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return models.Product{}, fmt.Errorf("invalid response - get product info")
-	}
+func (r *OrderRepository) CommitStock(ctx context.Context, reservationID string) error {
+	return r.ProductClient.CommitStock(ctx, reservationID)
+}
 
-	var response models.GetProductInfo
+func (r *OrderRepository) ReleaseStock(ctx context.Context, reservationID string) error {
+	return r.ProductClient.ReleaseStock(ctx, reservationID)
+}
 
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	if err != nil {
-		return models.Product{}, err
+// RestockItems puts returned items back on sale. authorization is the admin's own authorization
+// header.
+func (r *OrderRepository) RestockItems(ctx context.Context, authorization string, restockID string, items []models.ReturnItem) error {
+	param := models.RestockRequest{
+		RestockID: restockID,
+	}
+	for _, item := range items {
+		param.Items = append(param.Items, models.StockReservationItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
 	}
+	return r.ProductClient.RestockItems(ctx, authorization, param)
+}
 
-	product = response.Product
-	return product, nil
+// GetShippingAddress returns an address of the user's address book, the default address when
+// addressID is 0. authorization is the user's own authorization header.
+func (r *OrderRepository) GetShippingAddress(ctx context.Context, authorization string, addressID int64) (*models.Address, error) {
+	if addressID == 0 {
+		return r.UserClient.GetDefaultAddress(ctx, authorization)
+	}
+	return r.UserClient.GetAddress(ctx, authorization, addressID)
 }
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *OrderRepository) InsertReturnTx(ctx context.Context, tx *gorm.DB, orderReturn *models.OrderReturn) error {
+	err := tx.WithContext(ctx).Table("order_return").Create(orderReturn).Error
+	return err
+}
+
+func (r *OrderRepository) FindReturnsByOrderIDTx(ctx context.Context, tx *gorm.DB, orderID int64) ([]models.OrderReturn, error) {
+	var orderReturns []models.OrderReturn
+	err := tx.WithContext(ctx).Table("order_return").Where("order_id = ?", orderID).Order("id").Find(&orderReturns).Error
+	if err != nil {
+		return nil, err
+	}
+	return orderReturns, nil
+}
+
+func (r *OrderRepository) FindReturnsByOrderID(ctx context.Context, orderID int64) ([]models.OrderReturn, error) {
+	return r.FindReturnsByOrderIDTx(ctx, r.Database, orderID)
+}
+
+// FindReturns lists the returns in status, all of them when status is empty, newest first.
+func (r *OrderRepository) FindReturns(ctx context.Context, status string, limit int) ([]models.OrderReturn, error) {
+	var orderReturns []models.OrderReturn
+	query := r.Database.WithContext(ctx).Table("order_return")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&orderReturns).Error
+	if err != nil {
+		return nil, err
+	}
+	return orderReturns, nil
+}
+
+func (r *OrderRepository) FindReturnByID(ctx context.Context, returnID int64) (*models.OrderReturn, error) {
+	var orderReturn models.OrderReturn
+	err := r.Database.WithContext(ctx).Table("order_return").Where("id = ?", returnID).First(&orderReturn).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrReturnNotFound
+		}
+		return nil, err
+	}
+	return &orderReturn, nil
+}
+
+func (r *OrderRepository) GetReturnByIDForUpdateTx(ctx context.Context, tx *gorm.DB, returnID int64) (*models.OrderReturn, error) {
+	var orderReturn models.OrderReturn
+	err := tx.WithContext(ctx).Table("order_return").Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", returnID).First(&orderReturn).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrReturnNotFound
+		}
+		return nil, err
+	}
+	return &orderReturn, nil
+}
+
+func (r *OrderRepository) UpdateReturnTx(ctx context.Context, tx *gorm.DB, orderReturn *models.OrderReturn) error {
+	err := tx.WithContext(ctx).Table("order_return").Where("id = ?", orderReturn.ID).Updates(map[string]interface{}{
+		"status":      orderReturn.Status,
+		"restock":     orderReturn.Restock,
+		"admin_note":  orderReturn.AdminNote,
+		"update_time": time.Now(),
+	}).Error
+	return err
+}
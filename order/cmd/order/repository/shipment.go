@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"order/order/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *OrderRepository) FindOrderShipmentsByOrderIDTx(ctx context.Context, tx *gorm.DB, orderID int64) ([]models.OrderShipment, error) {
+	var shipments []models.OrderShipment
+	err := tx.WithContext(ctx).Table("order_shipment").Where("order_id = ?", orderID).Order("parcel_no").Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+// FindOrderShipmentsByOrderIDs returns the shipments of the orders, keyed by order ID.
+func (r *OrderRepository) FindOrderShipmentsByOrderIDs(ctx context.Context, orderIDs []int64) (map[int64][]models.OrderShipment, error) {
+	var shipments []models.OrderShipment
+	err := r.Database.WithContext(ctx).Table("order_shipment").Where("order_id IN ?", orderIDs).Order("order_id, parcel_no").Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+	shipmentsByOrderID := make(map[int64][]models.OrderShipment, len(orderIDs))
+	for _, shipment := range shipments {
+		shipmentsByOrderID[shipment.OrderID] = append(shipmentsByOrderID[shipment.OrderID], shipment)
+	}
+	return shipmentsByOrderID, nil
+}
+
+func (r *OrderRepository) UpsertOrderShipmentTx(ctx context.Context, tx *gorm.DB, shipment *models.OrderShipment) error {
+	err := tx.WithContext(ctx).Table("order_shipment").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "shipment_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"carrier", "tracking_number", "status", "update_time"}),
+	}).Create(shipment).Error
+	return err
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"order/order/models"
+)
+
+// FindTaxRatesByRegion returns the active tax rates of a region, both the region wide ones and the
+// ones for a single product category.
+func (r *OrderRepository) FindTaxRatesByRegion(ctx context.Context, region string) ([]models.TaxRate, error) {
+	var taxRates []models.TaxRate
+	err := r.Database.WithContext(ctx).Table("tax_rate").Where("region = ? AND active = true", region).Find(&taxRates).Error
+	if err != nil {
+		return nil, err
+	}
+	return taxRates, nil
+}
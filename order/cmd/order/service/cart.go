@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"order/order/infrastructure/log"
+	"order/order/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetCartItems reads the cart from Redis and falls back to Postgres, caching what it finds there.
+func (s *OrderService) GetCartItems(ctx context.Context, userID int64) ([]models.CartItem, error) {
+	//redis
+	items, err := s.OrderRepository.GetCartItemsFromRedis(ctx, userID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+		}).Errorf("s.OrderRepository.GetCartItemsFromRedis got error: %v", err)
+	}
+	if len(items) > 0 {
+		return items, nil
+	}
+
+	//db
+	items, err = s.OrderRepository.FindCartItemsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		err = s.OrderRepository.SetCartItems(ctx, userID, items)
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"userID": userID,
+			}).Errorf("s.OrderRepository.SetCartItems got error: %v", err)
+		}
+	}
+	return items, nil
+}
+
+func (s *OrderService) SetCartItemQuantity(ctx context.Context, userID int64, productID int64, quantity int) error {
+	err := s.OrderRepository.UpsertCartItem(ctx, userID, productID, quantity)
+	if err != nil {
+		return err
+	}
+	s.invalidateCart(ctx, userID)
+	return nil
+}
+
+// RemoveCartItems removes the given products from the cart, or empties it when productIDs is empty.
+func (s *OrderService) RemoveCartItems(ctx context.Context, userID int64, productIDs []int64) (int64, error) {
+	removed, err := s.OrderRepository.DeleteCartItems(ctx, userID, productIDs)
+	if err != nil {
+		return 0, err
+	}
+	s.invalidateCart(ctx, userID)
+	return removed, nil
+}
+
+// invalidateCart drops the cached cart after a write; Postgres stays the source of truth, so a
+// failure only means the next read may see the old cart until the cache expires.
+func (s *OrderService) invalidateCart(ctx context.Context, userID int64) {
+	err := s.OrderRepository.DeleteCartCache(ctx, userID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+		}).Errorf("s.OrderRepository.DeleteCartCache got error: %v", err)
+	}
+}
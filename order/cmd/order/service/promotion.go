@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+func (s *OrderService) GetPromotionsByCodes(ctx context.Context, codes []string) ([]models.Promotion, error) {
+	promotions, err := s.OrderRepository.FindPromotionsByCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (s *OrderService) GetPromotions(ctx context.Context, limit int) ([]models.Promotion, error) {
+	promotions, err := s.OrderRepository.FindPromotions(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (s *OrderService) GetPromotionByID(ctx context.Context, promotionID int64) (*models.Promotion, error) {
+	promotion, err := s.OrderRepository.FindPromotionByID(ctx, promotionID)
+	if err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+func (s *OrderService) CreatePromotion(ctx context.Context, promotion *models.Promotion) error {
+	return s.OrderRepository.InsertPromotion(ctx, promotion)
+}
+
+func (s *OrderService) UpdatePromotion(ctx context.Context, promotion *models.Promotion) error {
+	return s.OrderRepository.UpdatePromotion(ctx, promotion)
+}
+
+// claimPromotionUsagesTx redeems the promotions for the order. Each promotion row stays locked
+// until the transaction ends, so concurrent checkouts can't go over the global or per-user limits.
+func (s *OrderService) claimPromotionUsagesTx(ctx context.Context, tx *gorm.DB, order *models.Order, usages []models.PromotionUsage) error {
+	// lock in id order, so two checkouts with the same coupons can't deadlock
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].PromotionID < usages[j].PromotionID
+	})
+
+	for i := range usages {
+		promotion, err := s.OrderRepository.GetPromotionByIDForUpdateTx(ctx, tx, usages[i].PromotionID)
+		if err != nil {
+			return err
+		}
+		if promotion.UsageLimit > 0 && promotion.UsageCount >= promotion.UsageLimit {
+			return fmt.Errorf("%w: %s", constant.ErrCouponUsageLimitReached, promotion.Code)
+		}
+		if promotion.UsageLimitPerUser > 0 {
+			used, err := s.OrderRepository.CountPromotionUsagesByUserTx(ctx, tx, promotion.ID, order.UserID)
+			if err != nil {
+				return err
+			}
+			if used >= int64(promotion.UsageLimitPerUser) {
+				return fmt.Errorf("%w: %s already used %d times", constant.ErrCouponUsageLimitReached, promotion.Code, used)
+			}
+		}
+
+		err = s.OrderRepository.IncrementPromotionUsageTx(ctx, tx, promotion.ID)
+		if err != nil {
+			return err
+		}
+		usages[i].UserID = order.UserID
+		usages[i].OrderID = order.ID
+		err = s.OrderRepository.InsertPromotionUsageTx(ctx, tx, &usages[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releasePromotionUsagesTx gives back the promotions redeemed by an order that was cancelled or
+// failed, so they count towards neither the global nor the per-user limits any more.
+func (s *OrderService) releasePromotionUsagesTx(ctx context.Context, tx *gorm.DB, orderID int64) error {
+	usages, err := s.OrderRepository.DeletePromotionUsagesByOrderIDTx(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	// update in id order, like claimPromotionUsagesTx locks them
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].PromotionID < usages[j].PromotionID
+	})
+	for _, usage := range usages {
+		err = s.OrderRepository.DecrementPromotionUsageTx(ctx, tx, usage.PromotionID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
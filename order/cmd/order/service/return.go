@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"money"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CreateReturn requests the return of items of a completed order of the user. The quantity of a
+// product can't exceed what was ordered minus what earlier returns, rejected ones aside, already
+// sent back.
+func (s *OrderService) CreateReturn(ctx context.Context, userID int64, orderID int64, param models.CreateReturnRequest) (*models.OrderReturn, error) {
+	var orderReturn *models.OrderReturn
+
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		order, err := s.OrderRepository.GetOrderByIDForUpdateTx(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.UserID != userID {
+			return constant.ErrOrderNotFound
+		}
+		if order.Status != constant.OrderStatusCompleted {
+			return fmt.Errorf("%w: only completed orders can be returned, order is %s", constant.ErrInvalidReturn,
+				constant.OrderStatusTranslated[order.Status])
+		}
+		// nothing can be refunded for an order that wasn't paid
+		payment, err := s.OrderRepository.FindOrderPaymentByOrderIDTx(ctx, tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if payment == nil || payment.Status != constant.PaymentStatusSucceeded {
+			return fmt.Errorf("%w: the order has not been paid", constant.ErrInvalidReturn)
+		}
+
+		products, err := s.OrderRepository.FindOrderItemsByOrderIDTx(ctx, tx, order.ID)
+		if err != nil {
+			return err
+		}
+		previousReturns, err := s.OrderRepository.FindReturnsByOrderIDTx(ctx, tx, order.ID)
+		if err != nil {
+			return err
+		}
+		items, refundAmount, err := returnItems(order, products, previousReturns, param.Items)
+		if err != nil {
+			return err
+		}
+		itemsJSON, err := json.Marshal(items)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		orderReturn = &models.OrderReturn{
+			OrderID:      order.ID,
+			UserID:       order.UserID,
+			Status:       constant.ReturnStatusRequested,
+			Reason:       param.Reason,
+			Items:        string(itemsJSON),
+			RefundAmount: refundAmount,
+			CreateTime:   now,
+			UpdateTime:   now,
+		}
+		err = s.OrderRepository.InsertReturnTx(ctx, tx, orderReturn)
+		if err != nil {
+			return err
+		}
+		return s.recordReturnTx(ctx, tx, order, orderReturn, items, param.Reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orderReturn, nil
+}
+
+// ReviewReturn approves or rejects a requested return, status being constant.ReturnStatusApproved
+// or constant.ReturnStatusRejected.
+func (s *OrderService) ReviewReturn(ctx context.Context, returnID int64, status string, note string) (*models.OrderReturn, error) {
+	return s.transitionReturn(ctx, returnID, status, func(orderReturn *models.OrderReturn) {
+		orderReturn.AdminNote = note
+	}, nil)
+}
+
+// ReceiveReturn records that the items of an approved return are back and asks the payment
+// service for the refund. Putting the items back on sale is up to the caller.
+func (s *OrderService) ReceiveReturn(ctx context.Context, returnID int64, restock bool, note string) (*models.OrderReturn, error) {
+	return s.transitionReturn(ctx, returnID, constant.ReturnStatusReceived, func(orderReturn *models.OrderReturn) {
+		orderReturn.Restock = restock
+		if note != "" {
+			orderReturn.AdminNote = note
+		}
+	}, s.requestRefundTx)
+}
+
+// RetryRefund asks the payment service again for the refund of a return whose refund failed.
+func (s *OrderService) RetryRefund(ctx context.Context, returnID int64) (*models.OrderReturn, error) {
+	return s.transitionReturn(ctx, returnID, constant.ReturnStatusReceived, nil, s.requestRefundTx)
+}
+
+// ApplyRefundResult moves a received return to status, constant.ReturnStatusRefunded or
+// constant.ReturnStatusRefundFailed, once the payment service reports the refund. A paid refund
+// queues the order.refunded event.
+func (s *OrderService) ApplyRefundResult(ctx context.Context, event models.RefundEvent, status string) (*models.OrderReturn, error) {
+	var after func(context.Context, *gorm.DB, *models.OrderReturn) error
+	if status == constant.ReturnStatusRefunded {
+		after = func(ctx context.Context, tx *gorm.DB, orderReturn *models.OrderReturn) error {
+			return s.publishEventTx(ctx, tx, models.OrderRefundedEvent{
+				OrderID:    orderReturn.OrderID,
+				ReturnID:   orderReturn.ID,
+				UserID:     orderReturn.UserID,
+				Amount:     orderReturn.RefundAmount,
+				RefundID:   event.RefundID,
+				RefundedAt: time.Now().Format(time.RFC3339Nano),
+			})
+		}
+	}
+	return s.transitionReturn(ctx, event.ReturnID, status, func(orderReturn *models.OrderReturn) {
+		if event.FailureReason != "" {
+			orderReturn.AdminNote = event.FailureReason
+		}
+	}, after)
+}
+
+func (s *OrderService) GetReturn(ctx context.Context, returnID int64) (*models.OrderReturn, error) {
+	orderReturn, err := s.OrderRepository.FindReturnByID(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	return orderReturn, nil
+}
+
+// GetReturnsByOrderID lists the returns of an order, only those of userID when it is not zero.
+func (s *OrderService) GetReturnsByOrderID(ctx context.Context, userID int64, orderID int64) ([]models.OrderReturn, error) {
+	orderReturns, err := s.OrderRepository.FindReturnsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]models.OrderReturn, 0, len(orderReturns))
+	for _, orderReturn := range orderReturns {
+		if userID != 0 && orderReturn.UserID != userID {
+			continue
+		}
+		results = append(results, orderReturn)
+	}
+	return results, nil
+}
+
+func (s *OrderService) GetReturns(ctx context.Context, status string, limit int) ([]models.OrderReturn, error) {
+	orderReturns, err := s.OrderRepository.FindReturns(ctx, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return orderReturns, nil
+}
+
+func (s *OrderService) RestockItems(ctx context.Context, authorization string, restockID string, items []models.ReturnItem) error {
+	err := s.OrderRepository.RestockItems(ctx, authorization, restockID, items)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// transitionReturn moves a return to status if the return state machine allows it. update, when
+// set, changes the return before it is saved and after, when set, queues further events in the
+// same transaction.
+func (s *OrderService) transitionReturn(ctx context.Context, returnID int64, status string, update func(*models.OrderReturn),
+	after func(context.Context, *gorm.DB, *models.OrderReturn) error) (*models.OrderReturn, error) {
+	var orderReturn *models.OrderReturn
+
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		// lock the order first, like every other change to it, so the history is appended in turn
+		current, err := s.OrderRepository.FindReturnByID(ctx, returnID)
+		if err != nil {
+			return err
+		}
+		order, err := s.OrderRepository.GetOrderByIDForUpdateTx(ctx, tx, current.OrderID)
+		if err != nil {
+			return err
+		}
+		orderReturn, err = s.OrderRepository.GetReturnByIDForUpdateTx(ctx, tx, returnID)
+		if err != nil {
+			return err
+		}
+		if !constant.IsValidReturnTransition(orderReturn.Status, status) {
+			return fmt.Errorf("%w: %s to %s", constant.ErrInvalidReturnTransition, orderReturn.Status, status)
+		}
+
+		orderReturn.Status = status
+		if update != nil {
+			update(orderReturn)
+		}
+		err = s.OrderRepository.UpdateReturnTx(ctx, tx, orderReturn)
+		if err != nil {
+			return err
+		}
+
+		var items []models.ReturnItem
+		err = json.Unmarshal([]byte(orderReturn.Items), &items)
+		if err != nil {
+			return err
+		}
+		err = s.recordReturnTx(ctx, tx, order, orderReturn, items, orderReturn.AdminNote)
+		if err != nil {
+			return err
+		}
+		if after != nil {
+			return after(ctx, tx, orderReturn)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orderReturn, nil
+}
+
+// recordReturnTx appends the current status of a return to the order history and queues the
+// order.return_updated event.
+func (s *OrderService) recordReturnTx(ctx context.Context, tx *gorm.DB, order *models.Order, orderReturn *models.OrderReturn, items []models.ReturnItem, note string) error {
+	now := time.Now()
+	err := s.OrderRepository.AppendOrderHistoryTx(ctx, tx, order.ID, &models.OrderStatusHistory{
+		Status:     "return_" + orderReturn.Status,
+		ReturnID:   &orderReturn.ID,
+		Note:       note,
+		CreateTime: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	returnEvent := models.ReturnEvent{
+		ReturnID:     orderReturn.ID,
+		OrderID:      orderReturn.OrderID,
+		UserID:       orderReturn.UserID,
+		Status:       orderReturn.Status,
+		Items:        items,
+		RefundAmount: orderReturn.RefundAmount,
+		Restock:      orderReturn.Restock,
+		Note:         note,
+		OccurredAt:   now.Format(time.RFC3339Nano),
+	}
+	return s.publishEventTx(ctx, tx, returnEvent)
+}
+
+func (s *OrderService) requestRefundTx(ctx context.Context, tx *gorm.DB, orderReturn *models.OrderReturn) error {
+	refundRequestedEvent := models.RefundRequestedEvent{
+		ReturnID:    orderReturn.ID,
+		OrderID:     orderReturn.OrderID,
+		UserID:      orderReturn.UserID,
+		Amount:      orderReturn.RefundAmount,
+		Reason:      orderReturn.Reason,
+		RequestedAt: time.Now().Format(time.RFC3339Nano),
+	}
+	return s.publishEventTx(ctx, tx, refundRequestedEvent)
+}
+
+// returnItems validates the requested items against the order lines and the earlier returns and
+// prices their refund. A line refunds what was paid for it: its price after discounts, plus its tax
+// when the tax was charged on top. The refund of a partial return is the share of the units
+// returned so far minus the share already refunded, so returning a line in several parts never
+// refunds more, through rounding, than returning it at once.
+func returnItems(order *models.Order, products []models.CheckOutItem, previousReturns []models.OrderReturn, requested []models.ReturnItemRequest) ([]models.ReturnItem, money.Money, error) {
+	returned := make(map[int64]int)
+	for _, previous := range previousReturns {
+		if previous.Status == constant.ReturnStatusRejected {
+			continue
+		}
+		var items []models.ReturnItem
+		err := json.Unmarshal([]byte(previous.Items), &items)
+		if err != nil {
+			return nil, money.Money{}, err
+		}
+		for _, item := range items {
+			returned[item.ProductID] += item.Quantity
+		}
+	}
+
+	lines := make(map[int64]models.CheckOutItem, len(products))
+	for _, product := range products {
+		lines[product.ProductID] = product
+	}
+
+	items := make([]models.ReturnItem, 0, len(requested))
+	refundAmount := money.Zero(order.Amount.Currency)
+	seen := make(map[int64]bool, len(requested))
+	for _, req := range requested {
+		line, ok := lines[req.ProductID]
+		if !ok {
+			return nil, money.Money{}, fmt.Errorf("%w: product %d is not in the order", constant.ErrInvalidReturn, req.ProductID)
+		}
+		if seen[req.ProductID] {
+			return nil, money.Money{}, fmt.Errorf("%w: duplicate product %d", constant.ErrInvalidReturn, req.ProductID)
+		}
+		seen[req.ProductID] = true
+		if req.Quantity <= 0 || returned[req.ProductID]+req.Quantity > line.Quantity {
+			return nil, money.Money{}, fmt.Errorf("%w: %d of product %d can be returned, requested %d", constant.ErrInvalidReturn,
+				line.Quantity-returned[req.ProductID], req.ProductID, req.Quantity)
+		}
+
+		paid, err := linePaidAmount(order, line)
+		if err != nil {
+			return nil, money.Money{}, err
+		}
+		before := paid.MulRat(int64(returned[req.ProductID]), int64(line.Quantity))
+		after := paid.MulRat(int64(returned[req.ProductID]+req.Quantity), int64(line.Quantity))
+		refund, err := after.Sub(before)
+		if err != nil {
+			return nil, money.Money{}, err
+		}
+		refundAmount, err = refundAmount.Add(refund)
+		if err != nil {
+			return nil, money.Money{}, err
+		}
+		items = append(items, models.ReturnItem{
+			ProductID:    req.ProductID,
+			Quantity:     req.Quantity,
+			RefundAmount: refund,
+		})
+	}
+	return items, refundAmount, nil
+}
+
+func linePaidAmount(order *models.Order, line models.CheckOutItem) (money.Money, error) {
+	paid := line.Price.Mul(int64(line.Quantity))
+	var err error
+	for _, discount := range line.Discounts {
+		paid, err = paid.Sub(discount.Amount)
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+	if order.TaxMode == constant.TaxModeExclusive && line.Tax != nil {
+		paid, err = paid.Add(line.Tax.Amount)
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+	return paid, nil
+}
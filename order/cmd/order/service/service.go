@@ -2,9 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"order/order/cmd/order/repository"
+	constant "order/order/infrastructure/constans"
+	"order/order/kafka"
+	"order/order/kafka/schema"
 	"order/order/models"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -19,23 +26,18 @@ func NewOrderService(orderRepo *repository.OrderRepository) *OrderService {
 	}
 }
 
-func (s *OrderService) CheckIdempotency(ctx context.Context, token string) (bool, error) {
-	isExists, err := s.OrderRepository.CheckIdempotency(ctx, token)
+func (s *OrderService) GetIdempotencyLog(ctx context.Context, userID int64, token string) (*models.OrderRequestLog, error) {
+	requestLog, err := s.OrderRepository.FindIdempotencyLog(ctx, userID, token)
 	if err != nil {
-		return false, err
-	}
-	return isExists, err
-}
-
-func (s *OrderService) SaveIdempotency(ctx context.Context, token string) error {
-	err := s.OrderRepository.SaveIdempotency(ctx, token)
-	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return requestLog, nil
 }
 
-func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *models.Order, orderDetail *models.OrderDetail) (int64, error) {
+// SaveOrderAndOrderDetail stores the order with its items and first status, claims its idempotency
+// token when requestLog is not nil, redeems the promotions in promotionUsages and queues the
+// order.created event with the items to ship, all in one transaction.
+func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *models.Order, orderDetail *models.OrderDetail, items []models.CheckOutItem, requestLog *models.OrderRequestLog, promotionUsages []models.PromotionUsage) (int64, error) {
 	var orderID int64
 
 	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
@@ -49,7 +51,54 @@ func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *model
 			return err
 		}
 		orderID = order.ID
-		return nil
+
+		orderItems := make([]models.OrderItem, 0, len(items))
+		for i, item := range items {
+			orderItem, err := models.NewOrderItem(order.ID, i+1, item)
+			if err != nil {
+				return err
+			}
+			orderItems = append(orderItems, orderItem)
+		}
+		err = s.OrderRepository.InsertOrderItemsTx(ctx, tx, orderItems)
+		if err != nil {
+			return err
+		}
+		err = s.OrderRepository.AppendOrderHistoryTx(ctx, tx, order.ID, &models.OrderStatusHistory{
+			Status:     strings.ToLower(constant.OrderStatusTranslated[order.Status]),
+			CreateTime: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.claimPromotionUsagesTx(ctx, tx, order, promotionUsages)
+		if err != nil {
+			return err
+		}
+
+		if requestLog != nil {
+			requestLog.OrderID = order.ID
+			err = s.OrderRepository.InsertIdempotencyLogTx(ctx, tx, requestLog)
+			if err != nil {
+				return err
+			}
+		}
+
+		orderCreatedEvent := models.OrderCreatedEvent{
+			OrderID:         order.ID,
+			UserID:          order.UserID,
+			PaymentMethod:   order.PaymentMethod,
+			TotalAmount:     order.Amount,
+			ShippingAddress: order.ShippingAddress,
+		}
+		for _, item := range items {
+			orderCreatedEvent.Items = append(orderCreatedEvent.Items, models.OrderEventItem{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+			})
+		}
+		return s.publishEventTx(ctx, tx, orderCreatedEvent)
 	})
 	if err != nil {
 		return 0, err
@@ -58,21 +107,368 @@ func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *model
 
 }
 
-func (s *OrderService) GetOrderHistoryByUserID(ctx context.Context, param *models.OrderHistoryParam) ([]models.OrderHistoryResponse, error) {
-	orderHistories, err := s.OrderRepository.GetOrderHistoryByUserID(ctx, *param)
+// CancelOrder cancels the order and queues the order.cancelled event in the same transaction. An
+// order with a parcel already handed to the carrier can't be cancelled.
+func (s *OrderService) CancelOrder(ctx context.Context, orderID int64, userID int64) (*models.Order, error) {
+	var order *models.Order
+
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		order, err = s.updateOrderStatusTx(ctx, tx, &models.UpdateOrderStatusParam{
+			OrderID: orderID,
+			UserID:  userID,
+			Status:  constant.OrderStatusCancelled,
+		})
+		if err != nil {
+			return err
+		}
+		// checked once updateOrderStatusTx holds the lock on the order
+		err = s.checkNotShippedTx(ctx, tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		orderCancelledEvent := models.OrderCancelledEvent{
+			OrderID:     order.ID,
+			UserID:      order.UserID,
+			TotalAmount: order.Amount,
+			CancelledAt: time.Now().Format(time.RFC3339Nano),
+		}
+		return s.publishEventTx(ctx, tx, orderCancelledEvent)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return orderHistories, nil
+	return order, nil
+}
+
+// ForceOrderStatus moves an open order to param.Status when the state machine can't, for operators
+// fixing stuck orders. See checkForcedStatusTx for what may be forced. The reason and the admin are
+// kept in the order history. Forcing to Cancelled or Failed queues the order.cancelled event, which
+// has the payment service cancel or refund the charge and fulfillment stop the parcels, and gives
+// the promotions back.
+func (s *OrderService) ForceOrderStatus(ctx context.Context, param *models.ForceOrderStatusParam) (*models.Order, error) {
+	var order *models.Order
+
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		order, err = s.OrderRepository.GetOrderByIDForUpdateTx(ctx, tx, param.OrderID)
+		if err != nil {
+			return err
+		}
+		err = s.checkForcedStatusTx(ctx, tx, order, param.Status)
+		if err != nil {
+			return err
+		}
+		previousStatus := order.Status
+
+		err = s.OrderRepository.UpdateOrderStatusTx(ctx, tx, order.ID, param.Status)
+		if err != nil {
+			return err
+		}
+		if constant.IsAbandonedOrderStatus(param.Status) {
+			err = s.releasePromotionUsagesTx(ctx, tx, order.ID)
+			if err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		err = s.OrderRepository.AppendOrderHistoryTx(ctx, tx, order.ID, &models.OrderStatusHistory{
+			Status:     strings.ToLower(constant.OrderStatusTranslated[param.Status]),
+			Note:       fmt.Sprintf("forced from %s by admin %d: %s", strings.ToLower(constant.OrderStatusTranslated[previousStatus]), param.AdminID, param.Reason),
+			CreateTime: now,
+		})
+		if err != nil {
+			return err
+		}
+		err = s.publishEventTx(ctx, tx, models.OrderStatusChangedEvent{
+			OrderID:    order.ID,
+			UserID:     order.UserID,
+			FromStatus: strings.ToLower(constant.OrderStatusTranslated[previousStatus]),
+			ToStatus:   strings.ToLower(constant.OrderStatusTranslated[param.Status]),
+			Forced:     true,
+			Reason:     param.Reason,
+			ChangedAt:  now.Format(time.RFC3339Nano),
+		})
+		if err != nil {
+			return err
+		}
+		order.Status = param.Status
+
+		if !constant.IsAbandonedOrderStatus(param.Status) {
+			return nil
+		}
+		orderCancelledEvent := models.OrderCancelledEvent{
+			OrderID:     order.ID,
+			UserID:      order.UserID,
+			TotalAmount: order.Amount,
+			CancelledAt: now.Format(time.RFC3339Nano),
+		}
+		return s.publishEventTx(ctx, tx, orderCancelledEvent)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// checkForcedStatusTx tells whether order may be forced to status. Closed orders are never
+// reopened: their stock was given back or sold and their payment settled. An order is only forced
+// to Processing or Completed once paid, and only cancelled or failed while none of its parcels has
+// been handed to the carrier; after that the items come back through a return.
+func (s *OrderService) checkForcedStatusTx(ctx context.Context, tx *gorm.DB, order *models.Order, status int) error {
+	from := constant.OrderStatusTranslated[order.Status]
+	if order.Status == status {
+		return fmt.Errorf("%w: order is already %s", constant.ErrInvalidStatusTransition, from)
+	}
+	if order.Status != constant.OrderStatusCreated && order.Status != constant.OrderStatusProcessing {
+		return fmt.Errorf("%w: a %s order can't be reopened", constant.ErrInvalidStatusTransition, from)
+	}
+
+	switch status {
+	case constant.OrderStatusProcessing, constant.OrderStatusCompleted:
+		payment, err := s.OrderRepository.FindOrderPaymentByOrderIDTx(ctx, tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if payment == nil || payment.Status != constant.PaymentStatusSucceeded {
+			return fmt.Errorf("%w: the order has not been paid", constant.ErrInvalidStatusTransition)
+		}
+	case constant.OrderStatusCancelled, constant.OrderStatusFailed:
+		return s.checkNotShippedTx(ctx, tx, order.ID)
+	default:
+		return fmt.Errorf("%w: an order can't be forced to %s", constant.ErrInvalidStatusTransition,
+			constant.OrderStatusTranslated[status])
+	}
+	return nil
 }
 
-func (s *OrderService) GetProductInfo(ctx context.Context, productID int64) (models.Product, error) {
-	productInfo, err := s.OrderRepository.GetProductInfo(ctx, productID)
+// checkNotShippedTx refuses to abandon an order once one of its parcels has been handed to the
+// carrier: its stock is sold and the items can only come back through a return.
+func (s *OrderService) checkNotShippedTx(ctx context.Context, tx *gorm.DB, orderID int64) error {
+	shipments, err := s.OrderRepository.FindOrderShipmentsByOrderIDTx(ctx, tx, orderID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return models.Product{}, nil
+		return err
+	}
+	for _, shipment := range shipments {
+		if shipment.Status != constant.ShipmentStatusPending && shipment.Status != constant.ShipmentStatusCancelled {
+			return fmt.Errorf("%w: parcel %d has been handed to the carrier, return the items instead",
+				constant.ErrInvalidStatusTransition, shipment.ParcelNo)
 		}
-		return models.Product{}, err
 	}
-	return productInfo, nil
+	return nil
+}
+
+// ApplyPaymentResult records the payment of an order and moves the order to status. The payment is
+// recorded even when the order can no longer move to status, in which case the returned error
+// wraps constant.ErrInvalidStatusTransition.
+func (s *OrderService) ApplyPaymentResult(ctx context.Context, event models.PaymentEvent, status int) (*models.Order, error) {
+	var order *models.Order
+	var transitionErr error
+
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		order, err = s.updateOrderStatusTx(ctx, tx, &models.UpdateOrderStatusParam{
+			OrderID: event.OrderID,
+			Status:  status,
+		})
+		if errors.Is(err, constant.ErrInvalidStatusTransition) {
+			transitionErr = err
+		} else if err != nil {
+			return err
+		}
+
+		err = s.OrderRepository.UpsertOrderPaymentTx(ctx, tx, &models.OrderPayment{
+			OrderID:       event.OrderID,
+			PaymentID:     event.PaymentID,
+			Status:        event.Status,
+			Provider:      event.Provider,
+			ProviderRef:   event.ProviderRef,
+			FailureReason: event.FailureReason,
+			UpdateTime:    time.Now(),
+		})
+		if err != nil || transitionErr != nil {
+			return err
+		}
+		// the parcels may have been reported delivered before the payment event was handled
+		return s.completeIfDeliveredTx(ctx, tx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if transitionErr != nil {
+		return nil, transitionErr
+	}
+	return order, nil
+}
+
+// RecordOrderPayment records the payment of an order without moving the order.
+func (s *OrderService) RecordOrderPayment(ctx context.Context, event models.PaymentEvent) error {
+	return s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		_, err := s.OrderRepository.GetOrderByIDForUpdateTx(ctx, tx, event.OrderID)
+		if err != nil {
+			return err
+		}
+		return s.OrderRepository.UpsertOrderPaymentTx(ctx, tx, &models.OrderPayment{
+			OrderID:       event.OrderID,
+			PaymentID:     event.PaymentID,
+			Status:        event.Status,
+			Provider:      event.Provider,
+			ProviderRef:   event.ProviderRef,
+			FailureReason: event.FailureReason,
+			UpdateTime:    time.Now(),
+		})
+	})
+}
+
+func (s *OrderService) updateOrderStatusTx(ctx context.Context, tx *gorm.DB, param *models.UpdateOrderStatusParam) (*models.Order, error) {
+	order, err := s.OrderRepository.GetOrderByIDForUpdateTx(ctx, tx, param.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if param.UserID != 0 && order.UserID != param.UserID {
+		return nil, constant.ErrOrderNotFound
+	}
+	if !constant.IsValidStatusTransition(order.Status, param.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", constant.ErrInvalidStatusTransition,
+			constant.OrderStatusTranslated[order.Status], constant.OrderStatusTranslated[param.Status])
+	}
+
+	err = s.OrderRepository.UpdateOrderStatusTx(ctx, tx, order.ID, param.Status)
+	if err != nil {
+		return nil, err
+	}
+	if constant.IsAbandonedOrderStatus(param.Status) {
+		err = s.releasePromotionUsagesTx(ctx, tx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	err = s.OrderRepository.AppendOrderHistoryTx(ctx, tx, order.ID, &models.OrderStatusHistory{
+		Status:     strings.ToLower(constant.OrderStatusTranslated[param.Status]),
+		CreateTime: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.publishEventTx(ctx, tx, models.OrderStatusChangedEvent{
+		OrderID:    order.ID,
+		UserID:     order.UserID,
+		FromStatus: strings.ToLower(constant.OrderStatusTranslated[order.Status]),
+		ToStatus:   strings.ToLower(constant.OrderStatusTranslated[param.Status]),
+		ChangedAt:  now.Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return nil, err
+	}
+	order.Status = param.Status
+	return order, nil
+}
+
+// publishEventTx stores an event, in its envelope, in the outbox so it is only published once the
+// surrounding transaction commits. The relay worker delivers it to the topic of its type, keyed by
+// its order. An event that doesn't match its registered schema fails the transaction rather than
+// reach the consumers.
+func (s *OrderService) publishEventTx(ctx context.Context, tx *gorm.DB, event kafka.Event) error {
+	topic, err := kafka.TopicForEvent(event.EventType())
+	if err != nil {
+		return err
+	}
+	envelope, err := kafka.NewEnvelope(ctx, event)
+	if err != nil {
+		return err
+	}
+	err = schema.Validate(envelope.Type, envelope.Version, envelope.Payload)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return s.OrderRepository.InsertOutboxTx(ctx, tx, &models.OrderOutbox{
+		AggregateID:     event.EventOrderID(),
+		Topic:           topic,
+		EventKey:        kafka.OrderKey(event.EventOrderID()),
+		Payload:         string(payload),
+		Status:          constant.OutboxStatusPending,
+		NextAttemptTime: now,
+		CreateTime:      now,
+	})
+}
+
+func (s *OrderService) GetOutboxStats(ctx context.Context) (models.OutboxStats, error) {
+	stats, err := s.OrderRepository.GetOutboxStats(ctx)
+	if err != nil {
+		return models.OutboxStats{}, err
+	}
+	return stats, nil
+}
+
+func (s *OrderService) SearchOrderHistory(ctx context.Context, param *models.OrderHistoryParam) ([]models.OrderHistoryResponse, error) {
+	orderHistories, err := s.OrderRepository.SearchOrderHistory(ctx, *param)
+	if err != nil {
+		return nil, err
+	}
+	return orderHistories, nil
+}
+
+func (s *OrderService) GetOrderByID(ctx context.Context, orderID int64) (*models.OrderHistoryResponse, error) {
+	order, err := s.OrderRepository.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// GetOrderPayment returns the payment of an order, nil when the payment service hasn't reported on
+// it yet.
+func (s *OrderService) GetOrderPayment(ctx context.Context, orderID int64) (*models.OrderPayment, error) {
+	payment, err := s.OrderRepository.FindOrderPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (s *OrderService) GetProductsInfo(ctx context.Context, productIDs []int64, currency string) (map[int64]models.Product, error) {
+	products, err := s.OrderRepository.GetProductsInfo(ctx, productIDs, currency)
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (s *OrderService) ReserveStock(ctx context.Context, reservationID string, items []models.CheckOutItem) error {
+	err := s.OrderRepository.ReserveStock(ctx, reservationID, items)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *OrderService) CommitStock(ctx context.Context, reservationID string) error {
+	err := s.OrderRepository.CommitStock(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *OrderService) ReleaseStock(ctx context.Context, reservationID string) error {
+	err := s.OrderRepository.ReleaseStock(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *OrderService) GetShippingAddress(ctx context.Context, authorization string, addressID int64) (*models.Address, error) {
+	address, err := s.OrderRepository.GetShippingAddress(ctx, authorization, addressID)
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
 }
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ApplyShipmentUpdate records the new status of a parcel and completes the order once all its
+// parcels are delivered. An event older than the status already known is ignored.
+func (s *OrderService) ApplyShipmentUpdate(ctx context.Context, event models.ShipmentEvent) error {
+	return s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		order, err := s.OrderRepository.GetOrderByIDForUpdateTx(ctx, tx, event.OrderID)
+		if err != nil {
+			return err
+		}
+		shipments, err := s.OrderRepository.FindOrderShipmentsByOrderIDTx(ctx, tx, order.ID)
+		if err != nil {
+			return err
+		}
+		for _, shipment := range shipments {
+			if shipment.ShipmentID == event.ShipmentID && constant.ShipmentStatusRank[shipment.Status] >= constant.ShipmentStatusRank[event.Status] {
+				return nil
+			}
+		}
+
+		err = s.OrderRepository.UpsertOrderShipmentTx(ctx, tx, &models.OrderShipment{
+			ShipmentID:     event.ShipmentID,
+			OrderID:        event.OrderID,
+			ParcelNo:       event.ParcelNo,
+			ParcelCount:    event.ParcelCount,
+			Carrier:        event.Carrier,
+			TrackingNumber: event.TrackingNumber,
+			Status:         event.Status,
+			UpdateTime:     time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		return s.completeIfDeliveredTx(ctx, tx, order)
+	})
+}
+
+// completeIfDeliveredTx completes a processing order whose parcels have all been delivered.
+func (s *OrderService) completeIfDeliveredTx(ctx context.Context, tx *gorm.DB, order *models.Order) error {
+	if order.Status != constant.OrderStatusProcessing {
+		return nil
+	}
+	shipments, err := s.OrderRepository.FindOrderShipmentsByOrderIDTx(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+	if len(shipments) == 0 || len(shipments) < shipments[0].ParcelCount {
+		return nil
+	}
+	for _, shipment := range shipments {
+		if shipment.Status != constant.ShipmentStatusDelivered {
+			return nil
+		}
+	}
+	_, err = s.updateOrderStatusTx(ctx, tx, &models.UpdateOrderStatusParam{
+		OrderID: order.ID,
+		Status:  constant.OrderStatusCompleted,
+	})
+	return err
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+	"order/order/models"
+)
+
+func (s *OrderService) GetTaxRatesByRegion(ctx context.Context, region string) ([]models.TaxRate, error) {
+	taxRates, err := s.OrderRepository.FindTaxRatesByRegion(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	return taxRates, nil
+}
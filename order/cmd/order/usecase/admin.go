@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+)
+
+// maxExportedOrders bounds a CSV export, a larger one has to be split with narrower filters.
+const maxExportedOrders = 10000
+
+// SearchOrders returns a page of the orders of every user, or of req.UserID, matching the order
+// history filters of req.
+func (uc *OrderUseCase) SearchOrders(ctx context.Context, req models.AdminOrderSearchRequest) (*models.OrderHistoryPage, error) {
+	param, err := parseAdminOrderSearchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return uc.searchOrderHistory(ctx, param)
+}
+
+// ExportOrders returns all the orders matching the filters of req, from the req.Cursor on. The
+// limit of req is ignored and it fails with constant.ErrInvalidHistoryQuery when more than
+// maxExportedOrders orders match.
+func (uc *OrderUseCase) ExportOrders(ctx context.Context, req models.AdminOrderSearchRequest) ([]models.OrderHistoryResponse, error) {
+	req.Limit = 0
+	param, err := parseAdminOrderSearchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []models.OrderHistoryResponse
+	for {
+		param.Limit = maxOrderHistoryLimit
+		page, err := uc.searchOrderHistory(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, page.Orders...)
+		if len(orders) > maxExportedOrders {
+			return nil, fmt.Errorf("%w: more than %d orders match, narrow the filters", constant.ErrInvalidHistoryQuery, maxExportedOrders)
+		}
+		if page.NextCursor == "" {
+			return orders, nil
+		}
+		param.Cursor, err = decodeOrderHistoryCursor(page.NextCursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ForceOrderStatus moves an open order to a status the state machine may not allow. Stock is given
+// back when it is forced to Cancelled or Failed, which is refused once a parcel has been shipped.
+func (uc *OrderUseCase) ForceOrderStatus(ctx context.Context, param *models.ForceOrderStatusParam) (*models.Order, error) {
+	order, err := uc.OrderService.ForceOrderStatus(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	if constant.IsAbandonedOrderStatus(order.Status) {
+		uc.releaseStock(ctx, order.ReservationID)
+	}
+	return order, nil
+}
+
+func parseAdminOrderSearchRequest(req models.AdminOrderSearchRequest) (*models.OrderHistoryParam, error) {
+	if req.UserID < 0 {
+		return nil, fmt.Errorf("%w: invalid user_id", constant.ErrInvalidHistoryQuery)
+	}
+	return parseOrderHistoryRequest(req.UserID, req.OrderHistoryRequest)
+}
@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"money"
+	constant "order/order/infrastructure/constans"
+	"order/order/infrastructure/log"
+	"order/order/models"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxCartItems        = 100
+	maxCartItemQuantity = 1000
+)
+
+// GetCart returns the cart priced with the current price, in currency when it is set, and stock of
+// every product. Products that no longer exist or don't have enough stock are returned as
+// unavailable and left out of the totals.
+func (uc *OrderUseCase) GetCart(ctx context.Context, userID int64, currency string) (*models.CartResponse, error) {
+	items, err := uc.OrderService.GetCartItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart := &models.CartResponse{
+		Items: []models.CartLine{},
+	}
+	if len(items) == 0 {
+		return cart, nil
+	}
+
+	productIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	products, err := uc.OrderService.GetProductsInfo(ctx, productIDs, strings.ToUpper(currency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product info: %w", err)
+	}
+
+	for _, item := range items {
+		line := models.CartLine{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+		if product, ok := products[item.ProductID]; ok {
+			line.Name = product.Name
+			line.Price = product.Price
+			line.Stock = product.Stock
+			line.Subtotal = product.Price.Mul(int64(item.Quantity))
+			line.Available = item.Quantity <= product.Stock
+		}
+		if line.Available {
+			if cart.TotalQty == 0 {
+				cart.TotalAmount = money.Zero(line.Subtotal.Currency)
+			}
+			cart.TotalAmount, err = cart.TotalAmount.Add(line.Subtotal)
+			if err != nil {
+				return nil, err
+			}
+			cart.TotalQty += line.Quantity
+		}
+		cart.Items = append(cart.Items, line)
+	}
+	return cart, nil
+}
+
+// AddCartItem adds quantity of a product to the cart, on top of what is already there.
+func (uc *OrderUseCase) AddCartItem(ctx context.Context, userID int64, param models.AddCartItemRequest) error {
+	if param.Quantity <= 0 {
+		return fmt.Errorf("%w: invalid quantity for product %d", constant.ErrInvalidCheckout, param.ProductID)
+	}
+	items, err := uc.OrderService.GetCartItems(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	quantity := param.Quantity
+	inCart := false
+	for _, item := range items {
+		if item.ProductID == param.ProductID {
+			quantity += item.Quantity
+			inCart = true
+		}
+	}
+	if !inCart && len(items) >= maxCartItems {
+		return fmt.Errorf("%w: cart can hold at most %d products", constant.ErrInvalidCheckout, maxCartItems)
+	}
+
+	err = uc.validateCartItem(ctx, param.ProductID, quantity)
+	if err != nil {
+		return err
+	}
+	return uc.OrderService.SetCartItemQuantity(ctx, userID, param.ProductID, quantity)
+}
+
+// UpdateCartItem sets the quantity of a product already in the cart; a quantity of zero removes it.
+func (uc *OrderUseCase) UpdateCartItem(ctx context.Context, userID int64, productID int64, quantity int) error {
+	if quantity == 0 {
+		return uc.RemoveCartItem(ctx, userID, productID)
+	}
+	if quantity < 0 {
+		return fmt.Errorf("%w: invalid quantity for product %d", constant.ErrInvalidCheckout, productID)
+	}
+
+	items, err := uc.OrderService.GetCartItems(ctx, userID)
+	if err != nil {
+		return err
+	}
+	inCart := false
+	for _, item := range items {
+		if item.ProductID == productID {
+			inCart = true
+		}
+	}
+	if !inCart {
+		return fmt.Errorf("%w: product %d", constant.ErrCartItemNotFound, productID)
+	}
+
+	err = uc.validateCartItem(ctx, productID, quantity)
+	if err != nil {
+		return err
+	}
+	return uc.OrderService.SetCartItemQuantity(ctx, userID, productID, quantity)
+}
+
+func (uc *OrderUseCase) RemoveCartItem(ctx context.Context, userID int64, productID int64) error {
+	removed, err := uc.OrderService.RemoveCartItems(ctx, userID, []int64{productID})
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return fmt.Errorf("%w: product %d", constant.ErrCartItemNotFound, productID)
+	}
+	return nil
+}
+
+func (uc *OrderUseCase) ClearCart(ctx context.Context, userID int64) error {
+	_, err := uc.OrderService.RemoveCartItems(ctx, userID, nil)
+	return err
+}
+
+// CheckoutCart checks out the cart at the current product prices and removes the checked out
+// products from the cart once the order is created.
+func (uc *OrderUseCase) CheckoutCart(ctx context.Context, userID int64, authorization string, param models.CheckoutCartRequest) (int64, error) {
+	// a retried checkout finds the cart already emptied, answer with the order it created
+	if param.IdempotencyToken != "" {
+		requestLog, err := uc.OrderService.GetIdempotencyLog(ctx, userID, param.IdempotencyToken)
+		if err != nil {
+			return 0, err
+		}
+		if requestLog != nil {
+			return requestLog.OrderID, nil
+		}
+	}
+
+	cart, err := uc.GetCart(ctx, userID, param.Currency)
+	if err != nil {
+		return 0, err
+	}
+	if len(cart.Items) == 0 {
+		return 0, constant.ErrEmptyCart
+	}
+
+	checkoutRequest := models.CheckOutRequest{
+		UserID:            userID,
+		PaymentMethod:     param.PaymentMethod,
+		ShippingAddress:   param.ShippingAddress,
+		ShippingAddressID: param.ShippingAddressID,
+		Authorization:     authorization,
+		IdempotencyToken:  param.IdempotencyToken,
+		Currency:          param.Currency,
+		CouponCodes:       param.CouponCodes,
+	}
+	productIDs := make([]int64, 0, len(cart.Items))
+	for _, line := range cart.Items {
+		checkoutRequest.Items = append(checkoutRequest.Items, models.CheckOutItem{
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+			Price:     line.Price,
+		})
+		productIDs = append(productIDs, line.ProductID)
+	}
+
+	orderID, err := uc.CheckOutOrder(ctx, &checkoutRequest)
+	if err != nil {
+		return 0, err
+	}
+
+	// the order exists at this point, a cart that couldn't be emptied is only logged
+	_, err = uc.OrderService.RemoveCartItems(context.WithoutCancel(ctx), userID, productIDs)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":  userID,
+			"orderID": orderID,
+		}).Errorf("uc.OrderService.RemoveCartItems got error: %v", err)
+	}
+	return orderID, nil
+}
+
+func (uc *OrderUseCase) validateCartItem(ctx context.Context, productID int64, quantity int) error {
+	if quantity > maxCartItemQuantity {
+		return fmt.Errorf("%w: invalid quantity for product %d, maximum product qty is %d", constant.ErrInvalidCheckout, productID, maxCartItemQuantity)
+	}
+	products, err := uc.OrderService.GetProductsInfo(ctx, []int64{productID}, "")
+	if err != nil {
+		return fmt.Errorf("failed to get product info: %w", err)
+	}
+	product, ok := products[productID]
+	if !ok {
+		return fmt.Errorf("product with id %d not found: %w", productID, constant.ErrProductNotFound)
+	}
+	if quantity > product.Stock {
+		return fmt.Errorf("%w for product %d: available %d, requested %d", constant.ErrInsufficientStock, productID, product.Stock, quantity)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOrderHistoryLimit = 20
+	maxOrderHistoryLimit     = 100
+)
+
+// GetOrderHistoryByUserID returns a page of the user's orders matching the filters of req and the
+// cursor to the next page.
+func (uc *OrderUseCase) GetOrderHistoryByUserID(ctx context.Context, userID int64, req models.OrderHistoryRequest) (*models.OrderHistoryPage, error) {
+	param, err := parseOrderHistoryRequest(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	return uc.searchOrderHistory(ctx, param)
+}
+
+// searchOrderHistory returns the page of orders param selects and the cursor to the next page.
+func (uc *OrderUseCase) searchOrderHistory(ctx context.Context, param *models.OrderHistoryParam) (*models.OrderHistoryPage, error) {
+	// one order more than the page tells whether there is a next page
+	limit := param.Limit
+	param.Limit++
+	orders, err := uc.OrderService.SearchOrderHistory(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.OrderHistoryPage{
+		Orders: orders,
+	}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		cursor := models.OrderHistoryCursor{
+			Sort: param.Sort,
+			ID:   last.OrderID,
+		}
+		if param.Sort == constant.OrderHistorySortAmountDesc || param.Sort == constant.OrderHistorySortAmountAsc {
+			cursor.Amount = last.TotalAmount.Amount
+		}
+		page.NextCursor, err = encodeOrderHistoryCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func parseOrderHistoryRequest(userID int64, req models.OrderHistoryRequest) (*models.OrderHistoryParam, error) {
+	param := &models.OrderHistoryParam{
+		UserID:    userID,
+		MinAmount: req.MinAmount,
+		MaxAmount: req.MaxAmount,
+		Currency:  strings.ToUpper(req.Currency),
+		ProductID: req.ProductID,
+		Sort:      req.Sort,
+		Limit:     req.Limit,
+	}
+
+	// statuses are matched by name or by code, "created" and "0" both select Created orders
+	if req.Status != "" {
+		for _, name := range strings.Split(req.Status, ",") {
+			name = strings.TrimSpace(name)
+			status, ok := constant.ParseOrderStatus(name)
+			if !ok {
+				code, err := strconv.Atoi(name)
+				if _, known := constant.OrderStatusTranslated[code]; err != nil || !known {
+					return nil, fmt.Errorf("%w: unknown status %q", constant.ErrInvalidHistoryQuery, name)
+				}
+				status = code
+			}
+			param.Statuses = append(param.Statuses, status)
+		}
+	}
+
+	var err error
+	if req.From != "" {
+		param.From, err = parseHistoryTime(req.From, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if req.To != "" {
+		param.To, err = parseHistoryTime(req.To, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if param.From != nil && param.To != nil && !param.From.Before(*param.To) {
+		return nil, fmt.Errorf("%w: from must be before to", constant.ErrInvalidHistoryQuery)
+	}
+	if param.MinAmount != nil && param.MaxAmount != nil && *param.MinAmount > *param.MaxAmount {
+		return nil, fmt.Errorf("%w: min_amount is greater than max_amount", constant.ErrInvalidHistoryQuery)
+	}
+	if param.ProductID < 0 {
+		return nil, fmt.Errorf("%w: invalid product_id", constant.ErrInvalidHistoryQuery)
+	}
+
+	switch param.Sort {
+	case "":
+		param.Sort = constant.OrderHistorySortNewest
+	case constant.OrderHistorySortNewest, constant.OrderHistorySortOldest, constant.OrderHistorySortAmountDesc, constant.OrderHistorySortAmountAsc:
+	default:
+		return nil, fmt.Errorf("%w: unknown sort %q", constant.ErrInvalidHistoryQuery, param.Sort)
+	}
+
+	// amounts are only comparable within one currency
+	amountSort := param.Sort == constant.OrderHistorySortAmountDesc || param.Sort == constant.OrderHistorySortAmountAsc
+	if (param.MinAmount != nil || param.MaxAmount != nil || amountSort) && param.Currency == "" {
+		return nil, fmt.Errorf("%w: currency is required to filter or sort by amount", constant.ErrInvalidHistoryQuery)
+	}
+
+	if param.Limit == 0 {
+		param.Limit = defaultOrderHistoryLimit
+	}
+	if param.Limit < 0 || param.Limit > maxOrderHistoryLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", constant.ErrInvalidHistoryQuery, maxOrderHistoryLimit)
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeOrderHistoryCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != param.Sort {
+			return nil, fmt.Errorf("%w: cursor was issued for sort %q", constant.ErrInvalidHistoryQuery, cursor.Sort)
+		}
+		param.Cursor = cursor
+	}
+	return param, nil
+}
+
+// parseHistoryTime parses an RFC 3339 time or a date. A date given as the end of a range includes
+// the whole day.
+func parseHistoryTime(value string, end bool) (*time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return &t, nil
+	}
+	t, err = time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid time %q", constant.ErrInvalidHistoryQuery, value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func encodeOrderHistoryCursor(cursor models.OrderHistoryCursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+func decodeOrderHistoryCursor(value string) (*models.OrderHistoryCursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", constant.ErrInvalidHistoryQuery)
+	}
+	var cursor models.OrderHistoryCursor
+	err = json.Unmarshal(payload, &cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", constant.ErrInvalidHistoryQuery)
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,357 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"money"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+	"strings"
+	"time"
+)
+
+const maxCouponCodes = 5
+
+// applyPromotions validates the coupon codes and takes their discounts off the items, recording
+// the breakdown on every line. Promotions apply in the order the codes were given, each to what
+// the previous ones left of a line, so a line is never discounted below zero.
+func (uc *OrderUseCase) applyPromotions(ctx context.Context, codes []string, items []models.CheckOutItem, products map[int64]models.Product) ([]models.AppliedPromotion, error) {
+	for i := range items {
+		items[i].Discounts = nil
+	}
+	codes = normalizeCouponCodes(codes)
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	if len(codes) > maxCouponCodes {
+		return nil, fmt.Errorf("%w: at most %d coupons can be used on an order", constant.ErrInvalidCoupon, maxCouponCodes)
+	}
+
+	promotions, err := uc.OrderService.GetPromotionsByCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	promotionByCode := make(map[string]models.Promotion, len(promotions))
+	for _, promotion := range promotions {
+		promotionByCode[strings.ToUpper(promotion.Code)] = promotion
+	}
+	ordered := make([]models.Promotion, 0, len(codes))
+	for _, code := range codes {
+		promotion, ok := promotionByCode[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: coupon %s not found", constant.ErrInvalidCoupon, code)
+		}
+		if len(codes) > 1 && !promotion.Stackable {
+			return nil, fmt.Errorf("%w: coupon %s can't be combined with other coupons", constant.ErrInvalidCoupon, code)
+		}
+		ordered = append(ordered, promotion)
+	}
+
+	currency := items[0].Price.Currency
+	subtotal := money.Zero(currency)
+	remaining := make([]money.Money, len(items))
+	for i, item := range items {
+		remaining[i] = item.Price.Mul(int64(item.Quantity))
+		subtotal, err = subtotal.Add(remaining[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	applied := make([]models.AppliedPromotion, 0, len(ordered))
+	for _, promotion := range ordered {
+		discounts, err := promotionDiscounts(promotion, items, products, remaining, subtotal, now)
+		if err != nil {
+			return nil, err
+		}
+
+		total := money.Zero(currency)
+		for i, discount := range discounts {
+			if !discount.IsPositive() {
+				continue
+			}
+			items[i].Discounts = append(items[i].Discounts, models.LineDiscount{
+				Code:   promotion.Code,
+				Amount: discount,
+			})
+			remaining[i], err = remaining[i].Sub(discount)
+			if err != nil {
+				return nil, err
+			}
+			total, err = total.Add(discount)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !total.IsPositive() {
+			return nil, fmt.Errorf("%w: coupon %s doesn't apply to this order", constant.ErrInvalidCoupon, promotion.Code)
+		}
+		applied = append(applied, models.AppliedPromotion{
+			PromotionID: promotion.ID,
+			Code:        promotion.Code,
+			Type:        promotion.Type,
+			Amount:      total,
+		})
+	}
+	return applied, nil
+}
+
+// promotionDiscounts returns the discount of the promotion on every line, given what is left of
+// each line after the promotions applied before it.
+func promotionDiscounts(promotion models.Promotion, items []models.CheckOutItem, products map[int64]models.Product, remaining []money.Money, subtotal money.Money, now time.Time) ([]money.Money, error) {
+	if !promotion.Active || now.Before(promotion.StartTime) || (promotion.EndTime != nil && !now.Before(*promotion.EndTime)) {
+		return nil, fmt.Errorf("%w: coupon %s is not active", constant.ErrInvalidCoupon, promotion.Code)
+	}
+	if promotion.MinSpendAmount > 0 {
+		minSpend, err := promotionAmount(promotion, promotion.MinSpendAmount, items)
+		if err != nil {
+			return nil, err
+		}
+		if subtotal.Amount < minSpend.Amount {
+			return nil, fmt.Errorf("%w: coupon %s needs a minimum spend of %s", constant.ErrInvalidCoupon, promotion.Code, minSpend)
+		}
+	}
+
+	eligible := make([]bool, len(items))
+	for i, item := range items {
+		eligible[i] = promotion.CategoryID == nil || products[item.ProductID].CategoryID == *promotion.CategoryID
+	}
+
+	discounts := make([]money.Money, len(items))
+	for i := range discounts {
+		discounts[i] = money.Zero(remaining[i].Currency)
+	}
+
+	switch promotion.Type {
+	case constant.PromotionTypePercentage:
+		if promotion.DiscountBps <= 0 || promotion.DiscountBps > 10000 {
+			return nil, fmt.Errorf("%w: coupon %s has an invalid percentage", constant.ErrInvalidCoupon, promotion.Code)
+		}
+		for i := range items {
+			if eligible[i] {
+				discounts[i] = remaining[i].MulRat(promotion.DiscountBps, 10000)
+			}
+		}
+
+	case constant.PromotionTypeFixedAmount:
+		amount, err := promotionAmount(promotion, promotion.DiscountAmount, items)
+		if err != nil {
+			return nil, err
+		}
+		weights := make([]int64, len(items))
+		for i := range items {
+			if eligible[i] {
+				weights[i] = remaining[i].Amount
+			}
+		}
+		for i, share := range allocate(amount.Amount, weights) {
+			discounts[i] = money.New(share, amount.Currency)
+		}
+
+	case constant.PromotionTypeBuyXGetY:
+		if promotion.BuyQty <= 0 || promotion.GetQty <= 0 {
+			return nil, fmt.Errorf("%w: coupon %s has an invalid buy x get y rule", constant.ErrInvalidCoupon, promotion.Code)
+		}
+		for i, item := range items {
+			if !eligible[i] {
+				continue
+			}
+			freeQty := item.Quantity / (promotion.BuyQty + promotion.GetQty) * promotion.GetQty
+			discount := item.Price.Mul(int64(freeQty))
+			if discount.Amount > remaining[i].Amount {
+				discount = remaining[i]
+			}
+			discounts[i] = discount
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: coupon %s has unknown type %q", constant.ErrInvalidCoupon, promotion.Code, promotion.Type)
+	}
+	return discounts, nil
+}
+
+// promotionAmount returns an amount of the promotion in the currency of the order, converted at
+// the exchange rate locked in on the items when the order isn't in the promotion's currency.
+func promotionAmount(promotion models.Promotion, amount int64, items []models.CheckOutItem) (money.Money, error) {
+	currency := items[0].Price.Currency
+	if promotion.Currency == currency {
+		return money.New(amount, currency), nil
+	}
+	basePrice := items[0].BasePrice
+	if basePrice == nil || basePrice.Currency != promotion.Currency {
+		return money.Money{}, fmt.Errorf("%w: coupon %s is not valid for %s orders", constant.ErrInvalidCoupon, promotion.Code, currency)
+	}
+	return money.New(amount, promotion.Currency).Convert(currency, items[0].ExchangeRate)
+}
+
+// allocate splits amount over the lines in proportion to weights, rounding down and handing the
+// leftover minor units to the first lines, so the shares add up to amount exactly. amount is
+// capped at the sum of the weights, and no share exceeds its weight.
+func allocate(amount int64, weights []int64) []int64 {
+	shares := make([]int64, len(weights))
+	var total int64
+	for _, weight := range weights {
+		total += weight
+	}
+	if total <= 0 || amount <= 0 {
+		return shares
+	}
+	if amount > total {
+		amount = total
+	}
+
+	allocated := int64(0)
+	for i, weight := range weights {
+		share := new(big.Int).Mul(big.NewInt(amount), big.NewInt(weight))
+		shares[i] = share.Div(share, big.NewInt(total)).Int64()
+		allocated += shares[i]
+	}
+	for i := 0; allocated < amount; i = (i + 1) % len(weights) {
+		if shares[i] < weights[i] {
+			shares[i]++
+			allocated++
+		}
+	}
+	return shares
+}
+
+func normalizeCouponCodes(codes []string) []string {
+	normalized := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+	}
+	return normalized
+}
+
+// maxPromotionsListed bounds the promotion list of the admin API.
+const maxPromotionsListed = 500
+
+func (uc *OrderUseCase) GetPromotions(ctx context.Context) ([]models.Promotion, error) {
+	return uc.OrderService.GetPromotions(ctx, maxPromotionsListed)
+}
+
+func (uc *OrderUseCase) GetPromotion(ctx context.Context, promotionID int64) (*models.Promotion, error) {
+	return uc.OrderService.GetPromotionByID(ctx, promotionID)
+}
+
+func (uc *OrderUseCase) CreatePromotion(ctx context.Context, req models.PromotionRequest) (*models.Promotion, error) {
+	now := time.Now()
+	promotion, err := newPromotion(req, now)
+	if err != nil {
+		return nil, err
+	}
+	promotion.CreateTime = now
+	promotion.UpdateTime = now
+	err = uc.OrderService.CreatePromotion(ctx, promotion)
+	if err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// UpdatePromotion replaces the terms of a promotion. Orders that already redeemed it keep the
+// discount they got and still count towards its limits.
+func (uc *OrderUseCase) UpdatePromotion(ctx context.Context, promotionID int64, req models.PromotionRequest) (*models.Promotion, error) {
+	current, err := uc.OrderService.GetPromotionByID(ctx, promotionID)
+	if err != nil {
+		return nil, err
+	}
+	promotion, err := newPromotion(req, current.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	promotion.ID = current.ID
+	promotion.UsageCount = current.UsageCount
+	promotion.CreateTime = current.CreateTime
+	err = uc.OrderService.UpdatePromotion(ctx, promotion)
+	if err != nil {
+		return nil, err
+	}
+	return uc.OrderService.GetPromotionByID(ctx, promotionID)
+}
+
+// DeactivatePromotion stops a promotion from being redeemed. It isn't deleted, the orders that
+// redeemed it refer to it.
+func (uc *OrderUseCase) DeactivatePromotion(ctx context.Context, promotionID int64) (*models.Promotion, error) {
+	promotion, err := uc.OrderService.GetPromotionByID(ctx, promotionID)
+	if err != nil {
+		return nil, err
+	}
+	promotion.Active = false
+	err = uc.OrderService.UpdatePromotion(ctx, promotion)
+	if err != nil {
+		return nil, err
+	}
+	return uc.OrderService.GetPromotionByID(ctx, promotionID)
+}
+
+// newPromotion checks the terms of req, the same way checkout applies them, and returns the
+// promotion. A promotion without a start time starts at defaultStart.
+func newPromotion(req models.PromotionRequest, defaultStart time.Time) (*models.Promotion, error) {
+	promotion := &models.Promotion{
+		Code:              strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:              strings.TrimSpace(req.Name),
+		Type:              req.Type,
+		DiscountBps:       req.DiscountBps,
+		DiscountAmount:    req.DiscountAmount,
+		Currency:          strings.ToUpper(req.Currency),
+		CategoryID:        req.CategoryID,
+		BuyQty:            req.BuyQty,
+		GetQty:            req.GetQty,
+		MinSpendAmount:    req.MinSpendAmount,
+		UsageLimit:        req.UsageLimit,
+		UsageLimitPerUser: req.UsageLimitPerUser,
+		Stackable:         req.Stackable,
+		Active:            req.Active == nil || *req.Active,
+		StartTime:         defaultStart,
+		EndTime:           req.EndTime,
+	}
+	if req.StartTime != nil {
+		promotion.StartTime = *req.StartTime
+	}
+
+	if promotion.Code == "" || len(promotion.Code) > 50 || strings.ContainsAny(promotion.Code, " \t\r\n,") {
+		return nil, fmt.Errorf("%w: invalid code", constant.ErrInvalidPromotion)
+	}
+	if promotion.Name == "" || len(promotion.Name) > 255 {
+		return nil, fmt.Errorf("%w: invalid name", constant.ErrInvalidPromotion)
+	}
+	if !money.IsValidCurrency(promotion.Currency) {
+		return nil, fmt.Errorf("%w: unknown currency %s", constant.ErrInvalidPromotion, req.Currency)
+	}
+	switch promotion.Type {
+	case constant.PromotionTypePercentage:
+		if promotion.DiscountBps <= 0 || promotion.DiscountBps > 10000 {
+			return nil, fmt.Errorf("%w: discount_bps must be between 1 and 10000", constant.ErrInvalidPromotion)
+		}
+	case constant.PromotionTypeFixedAmount:
+		if promotion.DiscountAmount <= 0 {
+			return nil, fmt.Errorf("%w: discount_amount must be positive", constant.ErrInvalidPromotion)
+		}
+	case constant.PromotionTypeBuyXGetY:
+		if promotion.BuyQty <= 0 || promotion.GetQty <= 0 {
+			return nil, fmt.Errorf("%w: buy_qty and get_qty must be positive", constant.ErrInvalidPromotion)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", constant.ErrInvalidPromotion, promotion.Type)
+	}
+	if promotion.DiscountBps < 0 || promotion.DiscountAmount < 0 || promotion.BuyQty < 0 || promotion.GetQty < 0 ||
+		promotion.MinSpendAmount < 0 || promotion.UsageLimit < 0 || promotion.UsageLimitPerUser < 0 {
+		return nil, fmt.Errorf("%w: amounts, quantities and limits can't be negative", constant.ErrInvalidPromotion)
+	}
+	if promotion.CategoryID != nil && *promotion.CategoryID <= 0 {
+		return nil, fmt.Errorf("%w: invalid category_id", constant.ErrInvalidPromotion)
+	}
+	if promotion.EndTime != nil && !promotion.EndTime.After(promotion.StartTime) {
+		return nil, fmt.Errorf("%w: end_time must be after start_time", constant.ErrInvalidPromotion)
+	}
+	return promotion, nil
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	constant "order/order/infrastructure/constans"
+	"order/order/infrastructure/log"
+	"order/order/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+const maxReturnsListed = 100
+
+func (uc *OrderUseCase) CreateReturn(ctx context.Context, userID int64, orderID int64, param models.CreateReturnRequest) (*models.ReturnResponse, error) {
+	orderReturn, err := uc.OrderService.CreateReturn(ctx, userID, orderID, param)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnResponse(orderReturn)
+}
+
+func (uc *OrderUseCase) GetOrderReturns(ctx context.Context, userID int64, orderID int64) ([]models.ReturnResponse, error) {
+	orderReturns, err := uc.OrderService.GetReturnsByOrderID(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnResponses(orderReturns)
+}
+
+// GetReturns lists the returns in status for admins, e.g. the requested ones waiting for review.
+func (uc *OrderUseCase) GetReturns(ctx context.Context, status string) ([]models.ReturnResponse, error) {
+	switch status {
+	case "", constant.ReturnStatusRequested, constant.ReturnStatusApproved, constant.ReturnStatusRejected,
+		constant.ReturnStatusReceived, constant.ReturnStatusRefunded, constant.ReturnStatusRefundFailed:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %s", constant.ErrInvalidReturn, status)
+	}
+	orderReturns, err := uc.OrderService.GetReturns(ctx, status, maxReturnsListed)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnResponses(orderReturns)
+}
+
+func (uc *OrderUseCase) ApproveReturn(ctx context.Context, returnID int64, note string) (*models.ReturnResponse, error) {
+	orderReturn, err := uc.OrderService.ReviewReturn(ctx, returnID, constant.ReturnStatusApproved, note)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnResponse(orderReturn)
+}
+
+func (uc *OrderUseCase) RejectReturn(ctx context.Context, returnID int64, note string) (*models.ReturnResponse, error) {
+	orderReturn, err := uc.OrderService.ReviewReturn(ctx, returnID, constant.ReturnStatusRejected, note)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnResponse(orderReturn)
+}
+
+// ReceiveReturn records the items of an approved return as received, puts them back on sale when
+// restock is set and requests the refund. The items are restocked first, under an ID derived from
+// the return, so a receive that fails afterwards can be retried without restocking twice.
+// authorization is the admin's own authorization header, restocking is an admin action in the
+// product service as well.
+func (uc *OrderUseCase) ReceiveReturn(ctx context.Context, returnID int64, authorization string, param models.ReceiveReturnRequest) (*models.ReturnResponse, error) {
+	orderReturn, err := uc.OrderService.GetReturn(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	// a failed refund goes back to received as well, but only an approved return can be received
+	if orderReturn.Status != constant.ReturnStatusApproved {
+		return nil, fmt.Errorf("%w: %s to %s", constant.ErrInvalidReturnTransition, orderReturn.Status, constant.ReturnStatusReceived)
+	}
+
+	if param.Restock {
+		var items []models.ReturnItem
+		err = json.Unmarshal([]byte(orderReturn.Items), &items)
+		if err != nil {
+			return nil, err
+		}
+		err = uc.OrderService.RestockItems(ctx, authorization, fmt.Sprintf("return-%d", orderReturn.ID), items)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restock items: %w", err)
+		}
+	}
+
+	orderReturn, err = uc.OrderService.ReceiveReturn(ctx, returnID, param.Restock, param.Note)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnResponse(orderReturn)
+}
+
+// RetryRefund requests the refund of a return again after the payment service failed to pay it.
+func (uc *OrderUseCase) RetryRefund(ctx context.Context, returnID int64) (*models.ReturnResponse, error) {
+	orderReturn, err := uc.OrderService.GetReturn(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if orderReturn.Status != constant.ReturnStatusRefundFailed {
+		return nil, fmt.Errorf("%w: only a failed refund can be retried, return is %s", constant.ErrInvalidReturnTransition, orderReturn.Status)
+	}
+	orderReturn, err = uc.OrderService.RetryRefund(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnResponse(orderReturn)
+}
+
+// HandlePaymentRefunded marks a return as refunded.
+func (uc *OrderUseCase) HandlePaymentRefunded(ctx context.Context, event models.RefundEvent) error {
+	_, err := uc.OrderService.ApplyRefundResult(ctx, event, constant.ReturnStatusRefunded)
+	if err != nil {
+		return uc.ignoreStaleRefundEvent(event, err)
+	}
+	return nil
+}
+
+// HandlePaymentRefundFailed marks the refund of a return as failed, for an admin to retry it.
+func (uc *OrderUseCase) HandlePaymentRefundFailed(ctx context.Context, event models.RefundEvent) error {
+	_, err := uc.OrderService.ApplyRefundResult(ctx, event, constant.ReturnStatusRefundFailed)
+	if err != nil {
+		return uc.ignoreStaleRefundEvent(event, err)
+	}
+	return nil
+}
+
+// ignoreStaleRefundEvent drops refund events that can't be applied anymore, e.g. a redelivered
+// event of a return that is already refunded, so they are not retried forever.
+func (uc *OrderUseCase) ignoreStaleRefundEvent(event models.RefundEvent, err error) error {
+	if errors.Is(err, constant.ErrInvalidReturnTransition) || errors.Is(err, constant.ErrReturnNotFound) {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Warnf("refund event ignored: %v", err)
+		return nil
+	}
+	return err
+}
+
+func toReturnResponses(orderReturns []models.OrderReturn) ([]models.ReturnResponse, error) {
+	responses := make([]models.ReturnResponse, 0, len(orderReturns))
+	for i := range orderReturns {
+		response, err := toReturnResponse(&orderReturns[i])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+func toReturnResponse(orderReturn *models.OrderReturn) (*models.ReturnResponse, error) {
+	var items []models.ReturnItem
+	err := json.Unmarshal([]byte(orderReturn.Items), &items)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReturnResponse{
+		ID:           orderReturn.ID,
+		OrderID:      orderReturn.OrderID,
+		UserID:       orderReturn.UserID,
+		Status:       orderReturn.Status,
+		Reason:       orderReturn.Reason,
+		Items:        items,
+		RefundAmount: orderReturn.RefundAmount,
+		Restock:      orderReturn.Restock,
+		AdminNote:    orderReturn.AdminNote,
+		CreateTime:   orderReturn.CreateTime,
+		UpdateTime:   orderReturn.UpdateTime,
+	}, nil
+}
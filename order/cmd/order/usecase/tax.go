@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"money"
+	constant "order/order/infrastructure/constans"
+	"order/order/models"
+	"sort"
+	"strings"
+)
+
+// applyTaxes works out the tax of every line on what is left of it after discounts, at the rate of
+// the shipping region for the product's category, falling back to the region wide rate. A region
+// without rates, e.g. "ID-JK", falls back to the rates of its country, "ID". Lines
+// without a rate aren't taxed. It records the tax on the lines and returns the order's breakdown
+// per rate and the total tax.
+//
+// In exclusive mode the tax comes on top of the line amount; in inclusive mode the line amount
+// already contains it, so the tax is the part of it above amount / (1 + rate).
+func (uc *OrderUseCase) applyTaxes(ctx context.Context, region string, items []models.CheckOutItem, products map[int64]models.Product) ([]models.TaxBreakdown, money.Money, error) {
+	currency := items[0].Price.Currency
+	total := money.Zero(currency)
+	for i := range items {
+		items[i].Tax = nil
+	}
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if region == "" {
+		return nil, money.Money{}, fmt.Errorf("%w: unknown shipping region", constant.ErrInvalidCheckout)
+	}
+
+	taxRates, err := uc.OrderService.GetTaxRatesByRegion(ctx, region)
+	if err != nil {
+		return nil, money.Money{}, err
+	}
+	if country, _, ok := strings.Cut(region, "-"); ok && len(taxRates) == 0 {
+		region = country
+		taxRates, err = uc.OrderService.GetTaxRatesByRegion(ctx, region)
+		if err != nil {
+			return nil, money.Money{}, err
+		}
+	}
+	var regionRate *models.TaxRate
+	categoryRates := make(map[int]models.TaxRate, len(taxRates))
+	for i, taxRate := range taxRates {
+		if taxRate.RateBps < 0 {
+			return nil, money.Money{}, fmt.Errorf("%w: invalid tax rate %d for region %s", constant.ErrInvalidCheckout, taxRate.ID, region)
+		}
+		if taxRate.CategoryID == nil {
+			regionRate = &taxRates[i]
+			continue
+		}
+		categoryRates[*taxRate.CategoryID] = taxRate
+	}
+
+	breakdown := make(map[string]*models.TaxBreakdown)
+	var keys []string
+	for i, item := range items {
+		taxRate, ok := categoryRates[products[item.ProductID].CategoryID]
+		if !ok {
+			if regionRate == nil {
+				continue
+			}
+			taxRate = *regionRate
+		}
+
+		amount := item.Price.Mul(int64(item.Quantity))
+		for _, discount := range item.Discounts {
+			amount, err = amount.Sub(discount.Amount)
+			if err != nil {
+				return nil, money.Money{}, err
+			}
+		}
+		lineTax := lineTax(uc.TaxMode, taxRate, amount)
+		items[i].Tax = &lineTax
+
+		key := fmt.Sprintf("%s/%d", taxRate.Name, taxRate.RateBps)
+		orderTax, ok := breakdown[key]
+		if !ok {
+			orderTax = &models.TaxBreakdown{
+				Name:    taxRate.Name,
+				RateBps: taxRate.RateBps,
+				Taxable: money.Zero(currency),
+				Amount:  money.Zero(currency),
+			}
+			breakdown[key] = orderTax
+			keys = append(keys, key)
+		}
+		orderTax.Taxable, err = orderTax.Taxable.Add(lineTax.Taxable)
+		if err != nil {
+			return nil, money.Money{}, err
+		}
+		orderTax.Amount, err = orderTax.Amount.Add(lineTax.Amount)
+		if err != nil {
+			return nil, money.Money{}, err
+		}
+		total, err = total.Add(lineTax.Amount)
+		if err != nil {
+			return nil, money.Money{}, err
+		}
+	}
+
+	sort.Strings(keys)
+	taxes := make([]models.TaxBreakdown, 0, len(keys))
+	for _, key := range keys {
+		taxes = append(taxes, *breakdown[key])
+	}
+	return taxes, total, nil
+}
+
+// lineTax returns the tax on amount at taxRate, rounded half to even per line.
+func lineTax(mode string, taxRate models.TaxRate, amount money.Money) models.TaxBreakdown {
+	tax := models.TaxBreakdown{
+		Name:    taxRate.Name,
+		RateBps: taxRate.RateBps,
+	}
+	if mode == constant.TaxModeInclusive {
+		tax.Taxable = amount.MulRat(10000, 10000+taxRate.RateBps)
+		tax.Amount = money.New(amount.Amount-tax.Taxable.Amount, amount.Currency)
+		return tax
+	}
+	tax.Taxable = amount
+	tax.Amount = amount.MulRat(taxRate.RateBps, 10000)
+	return tax
+}
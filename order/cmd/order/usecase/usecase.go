@@ -5,159 +5,457 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"money"
 	"order/order/cmd/order/service"
 	constant "order/order/infrastructure/constans"
 	"order/order/infrastructure/log"
-	"order/order/kafka"
 	"order/order/models"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+const defaultIdempotencyTTL = 24 * time.Hour
+
 type OrderUseCase struct {
-	OrderService  *service.OrderService
-	KafkaProducer kafka.KafkaProducer
+	OrderService   *service.OrderService
+	IdempotencyTTL time.Duration
+	TaxMode        string
 }
 
-func NewOrderUseCase(orderService *service.OrderService, kafkaProducer kafka.KafkaProducer) *OrderUseCase {
+func NewOrderUseCase(orderService *service.OrderService, idempotencyTTL time.Duration, taxMode string) *OrderUseCase {
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = defaultIdempotencyTTL
+	}
+	if taxMode != constant.TaxModeInclusive {
+		taxMode = constant.TaxModeExclusive
+	}
 	return &OrderUseCase{
-		OrderService:  orderService,
-		KafkaProducer: kafkaProducer,
+		OrderService:   orderService,
+		IdempotencyTTL: idempotencyTTL,
+		TaxMode:        taxMode,
 	}
 }
 
 func (uc *OrderUseCase) CheckOutOrder(ctx context.Context, param *models.CheckOutRequest) (int64, error) {
 	var orderID int64
 
-	//check idempotency
+	//check idempotency, a repeated request gets the order it created the first time
 	if param.IdempotencyToken != "" {
-		isExists, err := uc.OrderService.CheckIdempotency(ctx, param.IdempotencyToken)
+		requestLog, err := uc.OrderService.GetIdempotencyLog(ctx, param.UserID, param.IdempotencyToken)
 		if err != nil {
 			return 0, err
 		}
-		if isExists {
-			return 0, errors.New("order already created, please check again")
+		if requestLog != nil {
+			return requestLog.OrderID, nil
 		}
 	}
+	//snapshot the address the order ships to
+	shippingAddress, err := uc.resolveShippingAddress(ctx, param)
+	if err != nil {
+		return 0, err
+	}
 	//validate product
-	err := uc.validateProducsts(ctx, param.Items)
+	productInfos, err := uc.validateProducsts(ctx, param.Currency, param.Items)
 	if err != nil {
 		return 0, err
 	}
-	//product amount
-	totalQty, totalAmount := uc.calculateOrderSummary(param.Items)
+	//lock in the exchange rate the order is charged at
+	exchangeRate := uc.lockExchangeRates(param.Items, productInfos)
+	//keep what was sold with the order, whatever happens to the products later
+	uc.snapshotProducts(param.Items, productInfos)
+	//apply coupons, before reserving stock so an invalid coupon doesn't hold any
+	promotions, err := uc.applyPromotions(ctx, param.CouponCodes, param.Items, productInfos)
+	if err != nil {
+		return 0, err
+	}
+	//tax the discounted lines at the rates of the shipping region
+	taxes, tax, err := uc.applyTaxes(ctx, param.ShippingRegion, param.Items, productInfos)
+	if err != nil {
+		return 0, err
+	}
+	//product amount, before reserving stock so a checkout refused here holds none
+	totalQty, subtotal, discount, err := uc.calculateOrderSummary(param.Items, promotions)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", constant.ErrInvalidCheckout, err)
+	}
+	totalAmount, err := subtotal.Sub(discount)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", constant.ErrInvalidCheckout, err)
+	}
+	if uc.TaxMode == constant.TaxModeExclusive {
+		totalAmount, err = totalAmount.Add(tax)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", constant.ErrInvalidCheckout, err)
+		}
+	}
+	//reserve stock for every item, all or nothing
+	reservationID := uuid.New().String()
+	err = uc.OrderService.ReserveStock(ctx, reservationID, param.Items)
+	if err != nil {
+		// a refused reservation holds nothing, any other failure, e.g. a timeout, may have
+		// reserved part of the items
+		if !errors.Is(err, constant.ErrInsufficientStock) {
+			uc.releaseStock(ctx, reservationID)
+		}
+		return 0, err
+	}
 
 	//construct order detail
-	products, orderHistory := uc.constructOrderDetail(param.Items)
+	discounts, taxesJSON := uc.constructOrderDetail(promotions, taxes)
 
 	//save order and detail
 	orderDetail := models.OrderDetail{
-		Products:     products,
-		OrderHistory: orderHistory,
+		Discounts: discounts,
+		Taxes:     taxesJSON,
 	}
 
 	order := models.Order{
-		UserID:          param.UserID,
-		Amount:          totalAmount,
-		TotalQty:        totalQty,
-		Status:          constant.OrderStatusCreated,
-		PaymentMethod:   param.PaymentMethod,
-		ShippingAddress: param.ShippingAddress,
+		UserID:                param.UserID,
+		Subtotal:              subtotal,
+		Discount:              discount,
+		Tax:                   tax,
+		TaxMode:               uc.TaxMode,
+		Amount:                totalAmount,
+		TotalQty:              totalQty,
+		Status:                constant.OrderStatusCreated,
+		PaymentMethod:         param.PaymentMethod,
+		ShippingAddress:       param.ShippingAddress,
+		ShippingRegion:        strings.ToUpper(strings.TrimSpace(param.ShippingRegion)),
+		ShippingAddressID:     param.ShippingAddressID,
+		ShippingAddressDetail: shippingAddress,
+		ReservationID:         reservationID,
+		ExchangeRate:          exchangeRate,
+	}
+	var requestLog *models.OrderRequestLog
+	if param.IdempotencyToken != "" {
+		now := time.Now()
+		requestLog = &models.OrderRequestLog{
+			UserID:           param.UserID,
+			IdempotencyToken: param.IdempotencyToken,
+			CreateTime:       now,
+			ExpireTime:       now.Add(uc.IdempotencyTTL),
+		}
 	}
-	orderID, err = uc.OrderService.SaveOrderAndOrderDetail(ctx, &order, &orderDetail)
+	promotionUsages := make([]models.PromotionUsage, 0, len(promotions))
+	for _, promotion := range promotions {
+		promotionUsages = append(promotionUsages, models.PromotionUsage{
+			PromotionID:    promotion.PromotionID,
+			DiscountAmount: promotion.Amount,
+			CreateTime:     time.Now(),
+		})
+	}
+	orderID, err = uc.OrderService.SaveOrderAndOrderDetail(ctx, &order, &orderDetail, param.Items, requestLog, promotionUsages)
 	if err != nil {
+		uc.releaseStock(ctx, reservationID)
+		if errors.Is(err, constant.ErrDuplicateIdempotency) {
+			// a concurrent request with the same token won the race, answer with its order
+			return uc.replayIdempotentOrder(ctx, param)
+		}
 		return 0, err
 	}
 
-	if param.IdempotencyToken != "" {
-		err = uc.OrderService.SaveIdempotency(ctx, param.IdempotencyToken)
-		if err != nil {
-			log.Logger.WithFields(logrus.Fields{
-				"err":   err.Error(),
-				"token": param.IdempotencyToken,
-			}).Info("uc.OrderService.SaveIdempotency got error")
-			return 0, err
-		}
-	}
-	//publish order created
-	orderCreatedEvent := models.OrderCreatedEvent{
-		OrderID:         orderID,
-		UserID:          param.UserID,
-		PaymentMethod:   param.PaymentMethod,
-		TotalAmount:     order.Amount,
-		ShippingAddress: param.ShippingAddress,
+	err = uc.OrderService.CommitStock(ctx, reservationID)
+	if err != nil {
+		// the stock is already taken out, a commit failure only leaves the reservation marked as pending
+		log.Logger.WithFields(logrus.Fields{
+			"orderID":       orderID,
+			"reservationID": reservationID,
+		}).Errorf("uc.OrderService.CommitStock got error: %v", err)
 	}
 
-	err = uc.KafkaProducer.PublishOrderCreated(ctx, orderCreatedEvent)
+	return orderID, nil
+}
+
+func (uc *OrderUseCase) replayIdempotentOrder(ctx context.Context, param *models.CheckOutRequest) (int64, error) {
+	requestLog, err := uc.OrderService.GetIdempotencyLog(ctx, param.UserID, param.IdempotencyToken)
 	if err != nil {
 		return 0, err
 	}
+	if requestLog == nil {
+		return 0, constant.ErrDuplicateIdempotency
+	}
+	log.Logger.WithFields(logrus.Fields{
+		"userID":  param.UserID,
+		"token":   param.IdempotencyToken,
+		"orderID": requestLog.OrderID,
+	}).Info("checkout replayed from idempotency token")
+	return requestLog.OrderID, nil
+}
 
-	return orderID, nil
+func (uc *OrderUseCase) releaseStock(ctx context.Context, reservationID string) {
+	if reservationID == "" {
+		return
+	}
+	// release even when the request that triggered it has been cancelled or timed out
+	err := uc.OrderService.ReleaseStock(context.WithoutCancel(ctx), reservationID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"reservationID": reservationID,
+		}).Errorf("uc.OrderService.ReleaseStock got error: %v", err)
+	}
 }
 
-func (uc *OrderUseCase) validateProducsts(ctx context.Context, items []models.CheckOutItem) error {
+// validateProducsts checks the items against the products priced in currency, or in their own
+// currency when it is empty, and returns the products.
+func (uc *OrderUseCase) validateProducsts(ctx context.Context, currency string, items []models.CheckOutItem) (map[int64]models.Product, error) {
+	productIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	products, err := uc.OrderService.GetProductsInfo(ctx, productIDs, strings.ToUpper(currency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product info: %w", err)
+	}
+
 	seen := map[int64]bool{}
+	currency = items[0].Price.Currency
 	for _, item := range items {
-		productInfo, err := uc.OrderService.GetProductInfo(ctx, item.ProductID)
-		if err != nil {
-			return fmt.Errorf("failed to get product info for product %d: %v", item.ProductID, err)
+		productInfo, ok := products[item.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("product with id %d not found: %w", item.ProductID, constant.ErrProductNotFound)
 		}
 
-		if productInfo.ID == 0 {
-			return fmt.Errorf("product with id %d not found", item.ProductID)
+		if !item.Price.Equal(productInfo.Price) {
+			return nil, fmt.Errorf("%w: price mismatch for product %d: expected %s, got %s", constant.ErrInvalidCheckout, item.ProductID, productInfo.Price, item.Price)
 		}
 
-		if item.Price != productInfo.Price {
-			return fmt.Errorf("price mismatch for product %d: expected %f, got %f", item.ProductID, productInfo.Price, item.Price)
+		if item.Price.Currency != currency {
+			return nil, fmt.Errorf("%w: product %d is priced in %s, other products in %s", constant.ErrInvalidCheckout, item.ProductID, item.Price.Currency, currency)
 		}
 
 		if seen[item.ProductID] {
-			return fmt.Errorf("duplicate product: %d", item.ProductID)
+			return nil, fmt.Errorf("%w: duplicate product: %d", constant.ErrInvalidCheckout, item.ProductID)
 		}
 		seen[item.ProductID] = true
-		if item.Quantity <= 0 || item.Quantity > 1000 {
-			return fmt.Errorf("invalid quantity for product %d, maximum product qty is 100", item.ProductID)
+		if item.Quantity <= 0 || item.Quantity > maxCartItemQuantity {
+			return nil, fmt.Errorf("%w: invalid quantity for product %d, maximum product qty is %d", constant.ErrInvalidCheckout, item.ProductID, maxCartItemQuantity)
 		}
 
-		if item.Price <= 0 {
-			return fmt.Errorf("invalid price for product %d", item.ProductID)
+		if !item.Price.IsPositive() {
+			return nil, fmt.Errorf("%w: invalid price for product %d", constant.ErrInvalidCheckout, item.ProductID)
 		}
 
 		if item.Quantity > productInfo.Stock {
-			return fmt.Errorf("insufficient stock for product %d: available %d, requested %d", item.ProductID, productInfo.Stock, item.Quantity)
+			return nil, fmt.Errorf("%w for product %d: available %d, requested %d", constant.ErrInsufficientStock, item.ProductID, productInfo.Stock, item.Quantity)
 		}
 	}
-	return nil
+	return products, nil
 }
 
-func (uc *OrderUseCase) calculateOrderSummary(items []models.CheckOutItem) (int, float64) {
+// lockExchangeRates keeps the base price and exchange rate of every converted item with the item,
+// and returns the rate of the order, "1" when the items weren't converted.
+func (uc *OrderUseCase) lockExchangeRates(items []models.CheckOutItem, products map[int64]models.Product) string {
+	exchangeRate := "1"
+	for i := range items {
+		product := products[items[i].ProductID]
+		items[i].BasePrice = product.BasePrice
+		items[i].ExchangeRate = product.ExchangeRate
+		if product.ExchangeRate != "" {
+			exchangeRate = product.ExchangeRate
+		}
+	}
+	return exchangeRate
+}
+
+// snapshotProducts replaces whatever product snapshot the items came with by the products as the
+// product service returned them.
+func (uc *OrderUseCase) snapshotProducts(items []models.CheckOutItem, products map[int64]models.Product) {
+	for i := range items {
+		product := products[items[i].ProductID]
+		price := product.Price
+		if product.BasePrice != nil {
+			price = *product.BasePrice
+		}
+		items[i].Product = &models.ProductSnapshot{
+			SKU:         product.SKU,
+			Name:        product.Name,
+			Description: product.Description,
+			CategoryID:  product.CategoryID,
+			Category:    product.CategoryName,
+			Price:       price,
+		}
+	}
+}
+
+// calculateOrderSummary adds up the line totals and the promotion discounts in minor units, so the
+// totals are exact; rounding only happens per line, when a discount is worked out.
+func (uc *OrderUseCase) calculateOrderSummary(items []models.CheckOutItem, promotions []models.AppliedPromotion) (int, money.Money, money.Money, error) {
 	var totalQty int
-	var totalAmount float64
+	currency := items[0].Price.Currency
+	subtotal := money.Zero(currency)
+	discount := money.Zero(currency)
 
 	for _, item := range items {
+		var err error
 		totalQty += item.Quantity
-		totalAmount += float64(item.Quantity) * item.Price
+		subtotal, err = subtotal.Add(item.Price.Mul(int64(item.Quantity)))
+		if err != nil {
+			return 0, money.Money{}, money.Money{}, err
+		}
+	}
+	for _, promotion := range promotions {
+		var err error
+		discount, err = discount.Add(promotion.Amount)
+		if err != nil {
+			return 0, money.Money{}, money.Money{}, err
+		}
+	}
+	return totalQty, subtotal, discount, nil
+}
+
+// resolveShippingAddress looks up the address book entry the order ships to and fills in the
+// shipping address and region of the request from it. It returns the address as JSON, to be kept
+// with the order. Free text addresses are refused: the region they are taxed at can't be trusted.
+func (uc *OrderUseCase) resolveShippingAddress(ctx context.Context, param *models.CheckOutRequest) (string, error) {
+	if param.ShippingAddressID == 0 && strings.TrimSpace(param.ShippingAddress) != "" {
+		return "", fmt.Errorf("%w: ship to an address of the address book", constant.ErrInvalidCheckout)
+	}
+	address, err := uc.OrderService.GetShippingAddress(ctx, param.Authorization, param.ShippingAddressID)
+	if err != nil {
+		return "", err
+	}
+	region := strings.ToUpper(strings.TrimSpace(address.TaxRegion()))
+	if address.Country == "" {
+		return "", fmt.Errorf("%w: the shipping address has no country", constant.ErrInvalidCheckout)
+	}
+	param.ShippingAddressID = address.ID
+	param.ShippingAddress = address.String()
+	param.ShippingRegion = region
+
+	address.IsDefault = false
+	addressJSON, err := json.Marshal(address)
+	if err != nil {
+		return "", err
+	}
+	return string(addressJSON), nil
+}
+
+func (uc *OrderUseCase) constructOrderDetail(promotions []models.AppliedPromotion, taxes []models.TaxBreakdown) (string, string) {
+	if promotions == nil {
+		promotions = []models.AppliedPromotion{}
+	}
+	discountJSON, _ := json.Marshal(promotions)
+	taxJSON, _ := json.Marshal(taxes)
+	return string(discountJSON), string(taxJSON)
+
+}
+
+// CancelOrder cancels an order owned by userID that has not been completed yet and none of whose
+// parcels has been handed to the carrier, and gives its stock back.
+func (uc *OrderUseCase) CancelOrder(ctx context.Context, orderID int64, userID int64) (*models.Order, error) {
+	order, err := uc.OrderService.CancelOrder(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	uc.releaseStock(ctx, order.ReservationID)
+	return order, nil
+}
+
+// HandlePaymentSucceeded starts processing a paid order.
+func (uc *OrderUseCase) HandlePaymentSucceeded(ctx context.Context, event models.PaymentEvent) error {
+	_, err := uc.OrderService.ApplyPaymentResult(ctx, event, constant.OrderStatusProcessing)
+	if err != nil {
+		return uc.ignoreStalePaymentEvent(event, err)
 	}
-	return totalQty, totalAmount
+	return nil
+}
+
+// HandlePaymentFailed fails the order and gives its stock back.
+func (uc *OrderUseCase) HandlePaymentFailed(ctx context.Context, event models.PaymentEvent) error {
+	order, err := uc.OrderService.ApplyPaymentResult(ctx, event, constant.OrderStatusFailed)
+	if err != nil {
+		return uc.ignoreStalePaymentEvent(event, err)
+	}
+	uc.releaseStock(ctx, order.ReservationID)
+	return nil
 }
 
-func (uc *OrderUseCase) constructOrderDetail(items []models.CheckOutItem) (string, string) {
-	productJson, _ := json.Marshal(items)
+// HandlePaymentCancelled records the payment of a cancelled order as cancelled or refunded.
+func (uc *OrderUseCase) HandlePaymentCancelled(ctx context.Context, event models.PaymentEvent) error {
+	err := uc.OrderService.RecordOrderPayment(ctx, event)
+	if errors.Is(err, constant.ErrOrderNotFound) {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Warnf("payment event ignored: %v", err)
+		return nil
+	}
+	return err
+}
 
-	history := []map[string]interface{}{
-		{"status": "created", "timestamp": time.Now()},
+// HandleShipmentUpdated records the tracking of a parcel, completing the order once all its
+// parcels are delivered.
+func (uc *OrderUseCase) HandleShipmentUpdated(ctx context.Context, event models.ShipmentEvent) error {
+	err := uc.OrderService.ApplyShipmentUpdate(ctx, event)
+	if errors.Is(err, constant.ErrOrderNotFound) {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Warnf("shipment event ignored: %v", err)
+		return nil
 	}
-	historyJSON, _ := json.Marshal(history)
-	return string(productJson), string(historyJSON)
+	return err
+}
 
+// ignoreStalePaymentEvent drops payment events that can't be applied anymore, e.g. for an order that
+// was cancelled in the meantime, so they are not retried forever. The payment itself has been
+// recorded, and the customer isn't left charged: the payment service refunds the payment of every
+// order it is told on order.cancelled was cancelled.
+func (uc *OrderUseCase) ignoreStalePaymentEvent(event models.PaymentEvent, err error) error {
+	if errors.Is(err, constant.ErrInvalidStatusTransition) || errors.Is(err, constant.ErrOrderNotFound) {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Warnf("payment event ignored: %v", err)
+		return nil
+	}
+	return err
 }
 
-func (uc *OrderUseCase) GetOrderHistoryByUserID(ctx context.Context, param *models.OrderHistoryParam) ([]models.OrderHistoryResponse, error) {
-	orderHistories, err := uc.OrderService.GetOrderHistoryByUserID(ctx, param)
+// GetOrderDetail returns an order of userID, or any order for an admin, with the current name of
+// its products, its payment and its returns. The product names are left empty when the product
+// service is unavailable rather than failing the whole order.
+func (uc *OrderUseCase) GetOrderDetail(ctx context.Context, orderID int64, userID int64, isAdmin bool) (*models.OrderDetailResponse, error) {
+	order, err := uc.OrderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && order.UserID != userID {
+		return nil, constant.ErrOrderNotFound
+	}
+
+	detail := &models.OrderDetailResponse{
+		OrderHistoryResponse: *order,
+		Products:             make([]models.OrderItemResponse, 0, len(order.Products)),
+	}
+	productIDs := make([]int64, 0, len(order.Products))
+	for _, item := range order.Products {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	products, err := uc.OrderService.GetProductsInfo(ctx, productIDs, "")
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"orderID": orderID,
+		}).Warnf("uc.OrderService.GetProductsInfo got error: %v", err)
+	}
+	for _, item := range order.Products {
+		detail.Products = append(detail.Products, models.OrderItemResponse{
+			CheckOutItem: item,
+			Name:         products[item.ProductID].Name,
+		})
+	}
+
+	detail.Payment, err = uc.OrderService.GetOrderPayment(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	orderReturns, err := uc.OrderService.GetReturnsByOrderID(ctx, 0, orderID)
+	if err != nil {
+		return nil, err
+	}
+	detail.Returns, err = toReturnResponses(orderReturns)
 	if err != nil {
 		return nil, err
 	}
-	return orderHistories, nil
+	return detail, nil
 }
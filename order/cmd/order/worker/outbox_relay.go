@@ -0,0 +1,159 @@
+package worker
+
+import (
+	"context"
+	"order/order/cmd/order/repository"
+	"order/order/config"
+	constant "order/order/infrastructure/constans"
+	"order/order/infrastructure/log"
+	"order/order/kafka"
+	"order/order/models"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxBatchSize    = 100
+	defaultOutboxMaxAttempts  = 10
+	maxOutboxRetryBackoff     = 5 * time.Minute
+)
+
+// OutboxRelay polls the order_outbox table and publishes pending events to Kafka. A message that
+// fails is retried with exponential backoff until it reaches MaxAttempts and is marked as failed.
+type OutboxRelay struct {
+	OrderRepository *repository.OrderRepository
+	KafkaProducer   *kafka.KafkaProducer
+	PollInterval    time.Duration
+	BatchSize       int
+	MaxAttempts     int
+
+	published     atomic.Uint64
+	publishErrors atomic.Uint64
+}
+
+func NewOutboxRelay(orderRepository *repository.OrderRepository, kafkaProducer *kafka.KafkaProducer, cfg config.OutboxConfig) *OutboxRelay {
+	relay := &OutboxRelay{
+		OrderRepository: orderRepository,
+		KafkaProducer:   kafkaProducer,
+		PollInterval:    cfg.PollInterval,
+		BatchSize:       cfg.BatchSize,
+		MaxAttempts:     cfg.MaxAttempts,
+	}
+	if relay.PollInterval <= 0 {
+		relay.PollInterval = defaultOutboxPollInterval
+	}
+	if relay.BatchSize <= 0 {
+		relay.BatchSize = defaultOutboxBatchSize
+	}
+	if relay.MaxAttempts <= 0 {
+		relay.MaxAttempts = defaultOutboxMaxAttempts
+	}
+	return relay
+}
+
+// Start relays messages until ctx is cancelled. A full batch is followed immediately by the next
+// one so a backlog drains without waiting for the poll interval.
+func (r *OutboxRelay) Start(ctx context.Context) {
+	log.Logger.Info("Outbox relay started")
+	for {
+		relayed, err := r.relayBatch(ctx)
+		if err != nil {
+			log.Logger.Errorf("r.relayBatch got error: %v", err)
+		}
+		if relayed == r.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Logger.Info("Outbox relay stopped")
+			return
+		case <-time.After(r.PollInterval):
+		}
+	}
+}
+
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	var relayed int
+
+	err := r.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		messages, err := r.OrderRepository.FetchPendingOutboxTx(ctx, tx, r.BatchSize)
+		if err != nil {
+			return err
+		}
+		failedOrders := map[int64]bool{}
+
+		for _, message := range messages {
+			// keep the events of one order in sequence: once one fails, the rest wait for the retry
+			if failedOrders[message.AggregateID] {
+				continue
+			}
+
+			err = r.KafkaProducer.Publish(ctx, message.Topic, message.EventKey, []byte(message.Payload))
+			if err != nil {
+				r.publishErrors.Add(1)
+				failedOrders[message.AggregateID] = true
+				if err := r.scheduleRetryTx(ctx, tx, message, err); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := r.OrderRepository.MarkOutboxSentTx(ctx, tx, message.ID); err != nil {
+				return err
+			}
+			r.published.Add(1)
+			relayed++
+		}
+		return nil
+	})
+	return relayed, err
+}
+
+func (r *OutboxRelay) scheduleRetryTx(ctx context.Context, tx *gorm.DB, message models.OrderOutbox, publishErr error) error {
+	message.Attempts++
+	message.LastError = publishErr.Error()
+	message.NextAttemptTime = time.Now().Add(retryBackoff(message.Attempts))
+	if message.Attempts >= r.MaxAttempts {
+		message.Status = constant.OutboxStatusFailed
+	}
+
+	log.Logger.WithFields(logrus.Fields{
+		"outboxID": message.ID,
+		"topic":    message.Topic,
+		"attempts": message.Attempts,
+		"status":   message.Status,
+	}).Errorf("r.KafkaProducer.Publish got error: %v", publishErr)
+
+	return r.OrderRepository.MarkOutboxRetryTx(ctx, tx, &message)
+}
+
+// Metrics reports how far the relay is behind together with its publish counters.
+func (r *OutboxRelay) Metrics(ctx context.Context) (models.OutboxMetrics, error) {
+	stats, err := r.OrderRepository.GetOutboxStats(ctx)
+	if err != nil {
+		return models.OutboxMetrics{}, err
+	}
+	return models.OutboxMetrics{
+		Pending:                 stats.Pending,
+		Failed:                  stats.Failed,
+		OldestPendingAgeSeconds: stats.OldestPendingAge.Seconds(),
+		Published:               r.published.Load(),
+		PublishErrors:           r.publishErrors.Load(),
+	}, nil
+}
+
+func retryBackoff(attempts int) time.Duration {
+	backoff := time.Second
+	for i := 1; i < attempts && backoff < maxOutboxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxOutboxRetryBackoff {
+		backoff = maxOutboxRetryBackoff
+	}
+	return backoff
+}
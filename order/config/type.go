@@ -1,11 +1,18 @@
 package config
 
+import "time"
+
 type Config struct {
 	App      AppConfig      `yaml:"app" validate:"required"`
 	Database DatabaseConfig `yaml:"database" validate:"required"`
 	Redis    RedisConfig    `yaml:"redis" validate:"required"`
 	Secret   SecretConfig   `yaml:"secret" validate:"required"`
 	Product  ProductConfig  `yaml:"product" validate:"required"`
+	User     UserConfig     `yaml:"user" validate:"required"`
+	Kafka    KafkaConfig    `yaml:"kafka" validate:"required"`
+	Outbox   OutboxConfig   `yaml:"outbox"`
+	Checkout CheckoutConfig `yaml:"checkout"`
+	Tax      TaxConfig      `yaml:"tax"`
 }
 
 type AppConfig struct {
@@ -32,5 +39,44 @@ type SecretConfig struct {
 }
 
 type ProductConfig struct {
-	Host string `yaml:"host" validate:"required"`
+	Host                    string        `yaml:"host" validate:"required"`
+	Timeout                 time.Duration `yaml:"timeout"`
+	MaxRetries              int           `yaml:"max_retries" mapstructure:"max_retries"`
+	RetryBaseDelay          time.Duration `yaml:"retry_base_delay" mapstructure:"retry_base_delay"`
+	RetryMaxDelay           time.Duration `yaml:"retry_max_delay" mapstructure:"retry_max_delay"`
+	BreakerFailureThreshold int           `yaml:"breaker_failure_threshold" mapstructure:"breaker_failure_threshold"`
+	BreakerOpenTimeout      time.Duration `yaml:"breaker_open_timeout" mapstructure:"breaker_open_timeout"`
+}
+
+type UserConfig struct {
+	Host    string        `yaml:"host" validate:"required"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type KafkaConfig struct {
+	Brokers       []string            `yaml:"brokers" validate:"required"`
+	ConsumerGroup string              `yaml:"consumer_group" mapstructure:"consumer_group" validate:"required"`
+	Consumer      KafkaConsumerConfig `yaml:"consumer"`
+}
+
+type KafkaConsumerConfig struct {
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" mapstructure:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size" mapstructure:"batch_size"`
+	MaxAttempts  int           `yaml:"max_attempts" mapstructure:"max_attempts"`
+}
+
+type CheckoutConfig struct {
+	// IdempotencyTTL is how long an idempotency token replays the order it created
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" mapstructure:"idempotency_ttl"`
+}
+
+type TaxConfig struct {
+	// Mode is "exclusive" when tax is added on top of product prices, "inclusive" when prices
+	// already contain it
+	Mode string `yaml:"mode"`
 }
@@ -1,24 +1,204 @@
 create table order_detail(
     id bigserial primary key,
-    products text not null ,
-    order_history text not null
+    discounts text not null default '[]',
+    taxes text not null default '[]'
 )
 
 create table orders (
     id bigserial primary key ,
     user_id bigint not null,
-    amount numeric not null,
+    subtotal_amount bigint not null,
+    subtotal_currency char(3) not null,
+    discount_amount bigint not null default 0,
+    discount_currency char(3) not null,
+    tax_amount bigint not null default 0,
+    tax_currency char(3) not null,
+    tax_mode varchar(20) not null default 'exclusive',
+    amount bigint not null,
+    currency char(3) not null default 'USD',
     total_qty integer not null,
     payment_method varchar(50),
     shipping_address text,
+    shipping_region varchar(20),
+    shipping_address_id bigint,
+    shipping_address_detail text not null default '',
     status integer not null,
     order_detail_id bigint references order_detail(id),
+    reservation_id varchar(64),
+    exchange_rate numeric(20, 10) not null default 1,
     create_time timestamp default current_timestamp,
     update_time timestamp default current_timestamp
 )
 
+create index idx_orders_user_id on orders (user_id, id);
+create index idx_orders_user_amount on orders (user_id, amount, id);
+create index idx_orders_user_create_time on orders (user_id, create_time);
+create index idx_orders_amount on orders (amount, id);
+create index idx_orders_create_time on orders (create_time);
+
+create table order_item(
+    id bigserial primary key,
+    order_id bigint not null references orders(id),
+    line_no integer not null,
+    product_id bigint not null,
+    quantity integer not null check (quantity > 0),
+    currency char(3) not null,
+    price_amount bigint not null,
+    base_price_amount bigint,
+    base_currency char(3),
+    exchange_rate varchar(30) not null default '',
+    discount_amount bigint not null default 0,
+    discounts text not null default '[]',
+    tax_name varchar(100),
+    tax_rate_bps bigint not null default 0,
+    tax_taxable_amount bigint not null default 0,
+    tax_amount bigint not null default 0,
+    product_sku varchar(64),
+    product_name varchar(255),
+    product_description text,
+    product_category_id integer,
+    product_category varchar(255),
+    product_price_amount bigint,
+    product_price_currency char(3),
+    constraint uq_order_item_line unique (order_id, line_no)
+);
+
+create index idx_order_item_product on order_item (product_id, order_id);
+
+create table order_status_history(
+    id bigserial primary key,
+    order_id bigint not null references orders(id),
+    status varchar(50) not null,
+    return_id bigint,
+    note text not null default '',
+    create_time timestamp not null default current_timestamp
+);
+
+create index idx_order_status_history_order on order_status_history (order_id, id);
+
 create table order_request_log(
     id bigserial primary key,
-    idempotency_token text unique not null,
-    crate_time timestamp default current_timestamp
+    user_id bigint not null,
+    idempotency_token text not null,
+    order_id bigint not null references orders(id),
+    create_time timestamp default current_timestamp,
+    expire_time timestamp not null,
+    constraint uq_order_request_log_user_token unique (user_id, idempotency_token)
 )
+
+create table order_outbox(
+    id bigserial primary key,
+    aggregate_id bigint not null,
+    topic varchar(100) not null,
+    event_key varchar(100) not null,
+    payload text not null,
+    status varchar(20) not null,
+    attempts integer not null default 0,
+    last_error text,
+    next_attempt_time timestamp not null default current_timestamp,
+    create_time timestamp default current_timestamp,
+    sent_time timestamp
+);
+
+create index idx_order_outbox_pending on order_outbox (status, next_attempt_time, id);
+
+create table order_payment(
+    order_id bigint primary key references orders(id),
+    payment_id bigint not null,
+    status varchar(20) not null,
+    provider varchar(50),
+    provider_ref varchar(100),
+    failure_reason text,
+    update_time timestamp default current_timestamp
+);
+
+create table cart_item(
+    id bigserial primary key,
+    user_id bigint not null,
+    product_id bigint not null,
+    quantity integer not null,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp,
+    constraint uq_cart_item_user_product unique (user_id, product_id)
+);
+
+create table promotion(
+    id bigserial primary key,
+    code varchar(50) not null,
+    name varchar(255) not null,
+    type varchar(20) not null check (type in ('percentage', 'fixed_amount', 'buy_x_get_y')),
+    discount_bps bigint not null default 0,
+    discount_amount bigint not null default 0,
+    currency char(3) not null,
+    category_id integer,
+    buy_qty integer not null default 0,
+    get_qty integer not null default 0,
+    min_spend_amount bigint not null default 0,
+    usage_limit integer not null default 0,
+    usage_limit_per_user integer not null default 0,
+    usage_count integer not null default 0,
+    stackable boolean not null default false,
+    active boolean not null default true,
+    start_time timestamp not null default current_timestamp,
+    end_time timestamp,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create unique index uq_promotion_code on promotion (upper(code));
+
+create table promotion_usage(
+    id bigserial primary key,
+    promotion_id bigint not null references promotion(id),
+    user_id bigint not null,
+    order_id bigint not null references orders(id),
+    discount_amount bigint not null,
+    discount_currency char(3) not null,
+    create_time timestamp default current_timestamp
+);
+
+create index idx_promotion_usage_user on promotion_usage (promotion_id, user_id);
+
+create table tax_rate(
+    id bigserial primary key,
+    region varchar(20) not null,
+    category_id integer,
+    name varchar(100) not null,
+    rate_bps bigint not null check (rate_bps >= 0),
+    active boolean not null default true,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create unique index uq_tax_rate_region_category on tax_rate (region, coalesce(category_id, 0)) where active;
+
+create table order_shipment(
+    shipment_id bigint primary key,
+    order_id bigint not null references orders(id),
+    parcel_no integer not null,
+    parcel_count integer not null,
+    carrier varchar(50),
+    tracking_number varchar(100),
+    status varchar(20) not null,
+    update_time timestamp default current_timestamp
+);
+
+create index idx_order_shipment_order on order_shipment (order_id, parcel_no);
+
+create table order_return(
+    id bigserial primary key,
+    order_id bigint not null references orders(id),
+    user_id bigint not null,
+    status varchar(20) not null,
+    reason text not null,
+    items text not null,
+    refund_amount bigint not null,
+    refund_currency char(3) not null,
+    restock boolean not null default false,
+    admin_note text not null default '',
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create index idx_order_return_order on order_return (order_id);
+create index idx_order_return_status on order_return (status, id);
@@ -0,0 +1,26 @@
+package constant
+
+import "errors"
+
+var (
+	ErrOrderNotFound           = errors.New("order not found")
+	ErrInvalidStatusTransition = errors.New("invalid order status transition")
+	ErrInsufficientStock       = errors.New("insufficient stock")
+	ErrDuplicateIdempotency    = errors.New("idempotency token already used")
+	ErrInvalidCheckout         = errors.New("invalid checkout")
+	ErrEmptyCart               = errors.New("cart is empty")
+	ErrCartItemNotFound        = errors.New("cart item not found")
+	ErrInvalidCoupon           = errors.New("invalid coupon")
+	ErrCouponUsageLimitReached = errors.New("coupon usage limit reached")
+	ErrPromotionNotFound       = errors.New("promotion not found")
+	ErrInvalidPromotion        = errors.New("invalid promotion")
+	ErrAddressNotFound         = errors.New("shipping address not found")
+	ErrReturnNotFound          = errors.New("return not found")
+	ErrInvalidReturn           = errors.New("invalid return")
+	ErrInvalidReturnTransition = errors.New("invalid return status transition")
+	ErrInvalidHistoryQuery     = errors.New("invalid order history query")
+
+	ErrProductNotFound           = errors.New("product not found")
+	ErrProductServiceUnavailable = errors.New("product service unavailable")
+	ErrUserServiceUnavailable    = errors.New("user service unavailable")
+)
@@ -1,5 +1,7 @@
 package constant
 
+import "strings"
+
 const (
 	OrderStatusCreated    = 0
 	OrderStatusProcessing = 1
@@ -15,3 +17,120 @@ var OrderStatusTranslated = map[int]string{
 	OrderStatusCancelled:  "Cancelled",
 	OrderStatusFailed:     "Failed",
 }
+
+// OrderStatusTransitions lists, for every status, the statuses an order may move to next.
+// Completed, Cancelled and Failed are terminal.
+var OrderStatusTransitions = map[int][]int{
+	OrderStatusCreated:    {OrderStatusProcessing, OrderStatusCancelled, OrderStatusFailed},
+	OrderStatusProcessing: {OrderStatusCompleted, OrderStatusCancelled, OrderStatusFailed},
+}
+
+func IsValidStatusTransition(from, to int) bool {
+	for _, next := range OrderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAbandonedOrderStatus reports whether an order in status was given up without being completed,
+// so what it reserved and redeemed is given back.
+func IsAbandonedOrderStatus(status int) bool {
+	return status == OrderStatusCancelled || status == OrderStatusFailed
+}
+
+// ParseOrderStatus maps a status name such as "processing" (case insensitive) to its code.
+func ParseOrderStatus(name string) (int, bool) {
+	for status, translated := range OrderStatusTranslated {
+		if strings.EqualFold(translated, name) {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
+)
+
+const (
+	PromotionTypePercentage  = "percentage"
+	PromotionTypeFixedAmount = "fixed_amount"
+	PromotionTypeBuyXGetY    = "buy_x_get_y"
+)
+
+const (
+	TaxModeExclusive = "exclusive"
+	TaxModeInclusive = "inclusive"
+)
+
+// Shipment statuses reported by the fulfillment service, ranked in the order a parcel goes through
+// them so a late event never moves a parcel back.
+const (
+	ShipmentStatusPending   = "pending"
+	ShipmentStatusShipped   = "shipped"
+	ShipmentStatusInTransit = "in_transit"
+	ShipmentStatusDelivered = "delivered"
+	ShipmentStatusCancelled = "cancelled"
+)
+
+var ShipmentStatusRank = map[string]int{
+	ShipmentStatusPending:   0,
+	ShipmentStatusShipped:   1,
+	ShipmentStatusInTransit: 2,
+	ShipmentStatusDelivered: 3,
+	ShipmentStatusCancelled: 3,
+}
+
+const (
+	RoleAdmin = "admin"
+	// RoleService is carried by the tokens the services sign to call each other's internal endpoints
+	RoleService = "service"
+)
+
+// Sort orders of the order history. Newest, by order ID, is the default.
+const (
+	OrderHistorySortNewest     = "newest"
+	OrderHistorySortOldest     = "oldest"
+	OrderHistorySortAmountDesc = "amount_desc"
+	OrderHistorySortAmountAsc  = "amount_asc"
+)
+
+// Statuses of the payment of an order, as reported by the payment service. The payment of a
+// cancelled order ends cancelled when it was never charged, or refunded when it was; both are final.
+const (
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusCancelled = "cancelled"
+	PaymentStatusRefunded  = "refunded"
+)
+
+// Return statuses. A return is requested by the customer, approved or rejected by an admin,
+// received back in the warehouse and then refunded. A failed refund can be requested again.
+const (
+	ReturnStatusRequested    = "requested"
+	ReturnStatusApproved     = "approved"
+	ReturnStatusRejected     = "rejected"
+	ReturnStatusReceived     = "received"
+	ReturnStatusRefunded     = "refunded"
+	ReturnStatusRefundFailed = "refund_failed"
+)
+
+var ReturnStatusTransitions = map[string][]string{
+	ReturnStatusRequested:    {ReturnStatusApproved, ReturnStatusRejected},
+	ReturnStatusApproved:     {ReturnStatusReceived},
+	ReturnStatusReceived:     {ReturnStatusRefunded, ReturnStatusRefundFailed},
+	ReturnStatusRefundFailed: {ReturnStatusReceived},
+}
+
+func IsValidReturnTransition(from, to string) bool {
+	for _, next := range ReturnStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package productclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	constant "order/order/infrastructure/constans"
+	"order/order/infrastructure/log"
+	"order/order/models"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTimeout                 = 500 * time.Millisecond
+	defaultMaxRetries              = 2
+	defaultRetryBaseDelay          = 50 * time.Millisecond
+	defaultRetryMaxDelay           = 500 * time.Millisecond
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenTimeout      = 10 * time.Second
+	serviceTokenTTL                = time.Minute
+)
+
+type Config struct {
+	Host string
+	// JWTSecret signs the service token the stock calls are authenticated with
+	JWTSecret string
+	// Timeout applies to every single attempt, retries included
+	Timeout time.Duration
+	// MaxRetries is the number of retries after the first attempt, 0 uses the default and a
+	// negative value disables retries
+	MaxRetries              int
+	RetryBaseDelay          time.Duration
+	RetryMaxDelay           time.Duration
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+}
+
+// Client calls the product service. Failures caused by the product service being unreachable or
+// unhealthy are reported as constant.ErrProductServiceUnavailable; a missing product as
+// constant.ErrProductNotFound.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	breaker    *circuitBreaker
+}
+
+// unavailableError marks a failed attempt that may succeed when retried.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unavailableError) Unwrap() []error {
+	return []error{constant.ErrProductServiceUnavailable, e.err}
+}
+
+func NewClient(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.RetryBaseDelay <= 0 {
+		cfg.RetryBaseDelay = defaultRetryBaseDelay
+	}
+	if cfg.RetryMaxDelay <= 0 {
+		cfg.RetryMaxDelay = defaultRetryMaxDelay
+	}
+	if cfg.BreakerFailureThreshold <= 0 {
+		cfg.BreakerFailureThreshold = defaultBreakerFailureThreshold
+	}
+	if cfg.BreakerOpenTimeout <= 0 {
+		cfg.BreakerOpenTimeout = defaultBreakerOpenTimeout
+	}
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{},
+		breaker:    newCircuitBreaker(cfg.BreakerFailureThreshold, cfg.BreakerOpenTimeout),
+	}
+}
+
+// GetProducts looks up all products in one call, priced in currency when it is set, and returns
+// them keyed by ID. IDs the product service doesn't know are left out; the lookup is read-only,
+// so it is retried like a GET.
+func (c *Client) GetProducts(ctx context.Context, productIDs []int64, currency string) (map[int64]models.Product, error) {
+	var response models.GetProductsBatchResponse
+
+	param := models.GetProductsBatchRequest{
+		IDs:      productIDs,
+		Currency: currency,
+	}
+	err := c.do(ctx, http.MethodPost, "/v1/product/batch", "", param, true, true, &response)
+	if err != nil {
+		return nil, err
+	}
+	products := make(map[int64]models.Product, len(response.Products))
+	for _, product := range response.Products {
+		products[product.ID] = product
+	}
+	return products, nil
+}
+
+// ReserveStock, CommitStock and ReleaseStock are keyed by the reservation ID, so the product
+// service applies a retried call only once and they are safe to retry.
+func (c *Client) ReserveStock(ctx context.Context, param models.ReserveStockRequest) error {
+	authorization, err := c.serviceAuthorization()
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/v1/product/stock/reserve", authorization, param, true, true, nil)
+}
+
+func (c *Client) CommitStock(ctx context.Context, reservationID string) error {
+	authorization, err := c.serviceAuthorization()
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/v1/product/stock/commit", authorization, models.StockReservationRequest{ReservationID: reservationID}, true, true, nil)
+}
+
+// ReleaseStock doesn't go through the circuit breaker: a release turned away while the breaker is
+// open would leave the stock reserved for good, so it is always tried.
+func (c *Client) ReleaseStock(ctx context.Context, reservationID string) error {
+	authorization, err := c.serviceAuthorization()
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/v1/product/stock/release", authorization, models.StockReservationRequest{ReservationID: reservationID}, true, false, nil)
+}
+
+// RestockItems puts returned items back on sale. It is keyed by the restock ID, so it is safe to
+// retry as well. Only admins may restock, authorization is the admin's own authorization header.
+func (c *Client) RestockItems(ctx context.Context, authorization string, param models.RestockRequest) error {
+	return c.do(ctx, http.MethodPost, "/v1/product/stock/restock", authorization, param, true, true, nil)
+}
+
+// serviceAuthorization returns the authorization header of the calls only other services may make
+// to the product service.
+func (c *Client) serviceAuthorization() (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"role": constant.RoleService,
+		"exp":  time.Now().Add(serviceTokenTTL).Unix(),
+	})
+	tokenString, err := token.SignedString([]byte(c.cfg.JWTSecret))
+	if err != nil {
+		return "", err
+	}
+	return "Bearer " + tokenString, nil
+}
+
+// do sends a request, through the circuit breaker when guarded, and decodes a 200 response into
+// result. authorization is sent as the Authorization header when set. Calls marked idempotent are
+// retried with jittered exponential backoff while the product service is unavailable.
+func (c *Client) do(ctx context.Context, method string, path string, authorization string, param interface{}, idempotent bool, guarded bool, result interface{}) error {
+	var payload []byte
+	if param != nil {
+		var err error
+		payload, err = json.Marshal(param)
+		if err != nil {
+			return err
+		}
+	}
+
+	maxAttempts := 1
+	if idempotent {
+		maxAttempts += c.cfg.MaxRetries
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if guarded && !c.breaker.allow() {
+			return fmt.Errorf("%w: circuit breaker open", constant.ErrProductServiceUnavailable)
+		}
+
+		err = c.attempt(ctx, method, path, authorization, payload, result)
+		if ctx.Err() != nil {
+			if guarded {
+				c.breaker.abort()
+			}
+			return fmt.Errorf("%w: %w", constant.ErrProductServiceUnavailable, ctx.Err())
+		}
+		var unavailable *unavailableError
+		if !errors.As(err, &unavailable) {
+			// success or an answer from a healthy product service, e.g. not found
+			if guarded {
+				c.breaker.success()
+			}
+			return err
+		}
+		if guarded {
+			c.breaker.failure()
+		}
+
+		log.Logger.WithFields(logrus.Fields{
+			"method":  method,
+			"path":    path,
+			"attempt": attempt,
+		}).Warnf("MicroService: product service call failed: %v", err)
+
+		if attempt == maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(c.backoff(attempt)):
+		}
+	}
+	return err
+}
+
+func (c *Client) attempt(ctx context.Context, method string, path string, authorization string, payload []byte, result interface{}) error {
+	attemptCtx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	url := c.cfg.Host + path
+	log.Logger.Info("MicroService: Calling product service URL: ", url)
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(attemptCtx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &unavailableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		if result == nil {
+			return nil
+		}
+		err = json.NewDecoder(resp.Body).Decode(result)
+		if err != nil {
+			return &unavailableError{err: fmt.Errorf("decode product service response: %w", err)}
+		}
+		return nil
+	case resp.StatusCode == http.StatusBadRequest:
+		var response models.ProductErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&response)
+		return fmt.Errorf("%w: %s", constant.ErrInvalidCheckout, response.ErrorMessage)
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", constant.ErrProductNotFound, path)
+	case resp.StatusCode == http.StatusConflict:
+		var response models.ProductErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&response)
+		return fmt.Errorf("%w: %s", constant.ErrInsufficientStock, response.ErrorMessage)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return &unavailableError{err: fmt.Errorf("product service responded with status %d", resp.StatusCode)}
+	default:
+		return fmt.Errorf("invalid response from product service %s: status %d", path, resp.StatusCode)
+	}
+}
+
+// backoff returns a random delay of up to RetryBaseDelay * 2^(attempt-1), capped at RetryMaxDelay.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.cfg.RetryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > c.cfg.RetryMaxDelay {
+		delay = c.cfg.RetryMaxDelay
+	}
+	return time.Duration(rand.Int64N(int64(delay)) + 1)
+}
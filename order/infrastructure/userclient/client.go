@@ -0,0 +1,94 @@
+package userclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	constant "order/order/infrastructure/constans"
+	"order/order/infrastructure/log"
+	"order/order/models"
+	"time"
+)
+
+const defaultTimeout = 500 * time.Millisecond
+
+type Config struct {
+	Host    string
+	Timeout time.Duration
+}
+
+// Client calls the user service on behalf of the user, with the user's own authorization header,
+// so the user service only hands out that user's data.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func NewClient(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// GetAddress returns an address of the user's address book.
+func (c *Client) GetAddress(ctx context.Context, authorization string, addressID int64) (*models.Address, error) {
+	var response models.GetAddressResponse
+	err := c.get(ctx, authorization, fmt.Sprintf("/api/v1/address/%d", addressID), &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Address, nil
+}
+
+// GetDefaultAddress returns the default address of the user, constant.ErrAddressNotFound when the
+// address book is empty.
+func (c *Client) GetDefaultAddress(ctx context.Context, authorization string) (*models.Address, error) {
+	var response models.GetAddressesResponse
+	err := c.get(ctx, authorization, "/api/v1/address", &response)
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range response.Addresses {
+		if address.IsDefault {
+			return &address, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no default address", constant.ErrAddressNotFound)
+}
+
+func (c *Client) get(ctx context.Context, authorization string, path string, result interface{}) error {
+	url := c.cfg.Host + path
+	log.Logger.Info("MicroService: Calling user service URL: ", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", constant.ErrUserServiceUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		err = json.NewDecoder(resp.Body).Decode(result)
+		if err != nil {
+			return fmt.Errorf("%w: decode user service response: %w", constant.ErrUserServiceUnavailable, err)
+		}
+		return nil
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", constant.ErrAddressNotFound, path)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: user service responded with status %d", constant.ErrUserServiceUnavailable, resp.StatusCode)
+	default:
+		return fmt.Errorf("invalid response from user service %s: status %d", path, resp.StatusCode)
+	}
+}
@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the events published by the order service.
+const (
+	EventTypeOrderCreated       = "OrderCreated"
+	EventTypeOrderCancelled     = "OrderCancelled"
+	EventTypeOrderStatusChanged = "OrderStatusChanged"
+	EventTypeOrderRefunded      = "OrderRefunded"
+	EventTypeReturnUpdated      = "ReturnUpdated"
+	EventTypeRefundRequested    = "RefundRequested"
+)
+
+// eventTopics routes every event type to the topic it is published on.
+var eventTopics = map[string]string{
+	EventTypeOrderCreated:       TopicOrderCreated,
+	EventTypeOrderCancelled:     TopicOrderCancelled,
+	EventTypeOrderStatusChanged: TopicOrderStatusChanged,
+	EventTypeOrderRefunded:      TopicOrderRefunded,
+	EventTypeReturnUpdated:      TopicReturnUpdated,
+	EventTypeRefundRequested:    TopicRefundRequested,
+}
+
+// Event is a domain event of an order. EventVersion is bumped whenever the payload changes in a way
+// consumers of the previous version can't read.
+type Event interface {
+	EventType() string
+	EventVersion() int
+	EventOrderID() int64
+}
+
+// Envelope wraps the payload of every event published by the order service. CorrelationID is the
+// ID of the request that caused the event, when there was one.
+type Envelope struct {
+	EventID       string          `json:"event_id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// NewEnvelope wraps event in a new envelope, taking the correlation ID from the request ID
+// middleware.RequestLogger put in ctx.
+func NewEnvelope(ctx context.Context, event Event) (*Envelope, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	correlationID, _ := ctx.Value("requestID").(string)
+	return &Envelope{
+		EventID:       uuid.New().String(),
+		Type:          event.EventType(),
+		Version:       event.EventVersion(),
+		OccurredAt:    time.Now().UTC(),
+		CorrelationID: correlationID,
+		Payload:       payload,
+	}, nil
+}
+
+// EventTypes lists the types of the events published by the order service.
+func EventTypes() []string {
+	eventTypes := make([]string, 0, len(eventTopics))
+	for eventType := range eventTopics {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return eventTypes
+}
+
+// TopicForEvent returns the topic events of eventType are published on.
+func TopicForEvent(eventType string) (string, error) {
+	topic, ok := eventTopics[eventType]
+	if !ok {
+		return "", fmt.Errorf("no topic for event type %q", eventType)
+	}
+	return topic, nil
+}
+
+// OrderKey is the message key of the events of an order. The producer partitions by key, so all
+// the events of one order land on the same partition and are consumed in the order they were
+// published.
+func OrderKey(orderID int64) string {
+	return strconv.FormatInt(orderID, 10)
+}
@@ -2,37 +2,49 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"order/order/models"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 )
 
+const (
+	TopicOrderCreated       = "order.created"
+	TopicOrderCancelled     = "order.cancelled"
+	TopicOrderStatusChanged = "order.status_changed"
+	TopicOrderRefunded      = "order.refunded"
+	TopicPaymentSucceeded   = "payment.succeeded"
+	TopicPaymentFailed      = "payment.failed"
+	TopicPaymentCancelled   = "payment.cancelled"
+	TopicShipmentUpdated    = "shipment.updated"
+
+	TopicReturnUpdated       = "order.return_updated"
+	TopicRefundRequested     = "refund.requested"
+	TopicPaymentRefunded     = "payment.refunded"
+	TopicPaymentRefundFailed = "payment.refund_failed"
+)
+
 type KafkaProducer struct {
 	writer *kafka.Writer
 }
 
-func NewKafkaProducer(brokers []string, topic string) *KafkaProducer {
+func NewKafkaProducer(brokers []string) *KafkaProducer {
 	writer := &kafka.Writer{
-		Addr:     kafka.TCP(brokers...),
-		Topic:    topic,
-		Balancer: &kafka.LeastBytes{},
+		Addr: kafka.TCP(brokers...),
+		// messages with the same key, the events of one order, always go to the same partition
+		Balancer: &kafka.Hash{},
+		// messages are written one at a time, don't wait for a batch to fill up
+		BatchTimeout: 10 * time.Millisecond,
 	}
 	return &KafkaProducer{writer: writer}
 }
 
-func (p *KafkaProducer) PublishOrderCreated(ctx context.Context, event interface{}) error {
-	value, err := json.Marshal(event)
-	if err != nil {
-		return err
-	}
+func (p *KafkaProducer) Publish(ctx context.Context, topic string, key string, value []byte) error {
 	msg := kafka.Message{
-		Key:   []byte(fmt.Sprintf("order-%d", event.(models.OrderCreatedEvent).OrderID)),
+		Topic: topic,
+		Key:   []byte(key),
 		Value: value,
 	}
 	return p.writer.WriteMessages(ctx, msg)
-
 }
 
 func (p *KafkaProducer) Close() error {
@@ -0,0 +1,190 @@
+package schema_test
+
+import (
+	"context"
+	"encoding/json"
+	"money"
+	"order/order/kafka"
+	"order/order/kafka/schema"
+	"order/order/models"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// sampleEvents holds an event of every type the order service publishes, every field set.
+func sampleEvents() []kafka.Event {
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC).Format(time.RFC3339Nano)
+	amount := money.New(2599, "USD")
+	return []kafka.Event{
+		models.OrderCreatedEvent{
+			OrderID:         1,
+			UserID:          2,
+			TotalAmount:     amount,
+			PaymentMethod:   "card",
+			ShippingAddress: "1 Main Street",
+			Items:           []models.OrderEventItem{{ProductID: 3, Quantity: 2}},
+		},
+		models.OrderCancelledEvent{
+			OrderID:     1,
+			UserID:      2,
+			TotalAmount: amount,
+			CancelledAt: now,
+		},
+		models.OrderStatusChangedEvent{
+			OrderID:    1,
+			UserID:     2,
+			FromStatus: "processing",
+			ToStatus:   "cancelled",
+			Forced:     true,
+			Reason:     "lost parcel",
+			ChangedAt:  now,
+		},
+		models.OrderRefundedEvent{
+			OrderID:    1,
+			ReturnID:   4,
+			UserID:     2,
+			Amount:     amount,
+			RefundID:   5,
+			RefundedAt: now,
+		},
+		models.ReturnEvent{
+			ReturnID:     4,
+			OrderID:      1,
+			UserID:       2,
+			Status:       "received",
+			Items:        []models.ReturnItem{{ProductID: 3, Quantity: 1, RefundAmount: amount}},
+			RefundAmount: amount,
+			Restock:      true,
+			Note:         "box damaged",
+			OccurredAt:   now,
+		},
+		models.RefundRequestedEvent{
+			ReturnID:    4,
+			OrderID:     1,
+			UserID:      2,
+			Amount:      amount,
+			Reason:      "too small",
+			RequestedAt: now,
+		},
+	}
+}
+
+func TestEveryEventTypeHasASample(t *testing.T) {
+	sampled := map[string]bool{}
+	for _, event := range sampleEvents() {
+		sampled[event.EventType()] = true
+	}
+	for _, eventType := range kafka.EventTypes() {
+		if !sampled[eventType] {
+			t.Errorf("no sample event of type %s", eventType)
+		}
+	}
+}
+
+func TestEventsMatchTheirSchema(t *testing.T) {
+	registry, err := schema.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), "requestID", "request-1")
+	for _, event := range sampleEvents() {
+		envelope, err := kafka.NewEnvelope(ctx, event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = registry.Validate(envelope.Type, envelope.Version, envelope.Payload)
+		if err != nil {
+			t.Errorf("%s: %v", envelope.Type, err)
+		}
+		encoded, err := json.Marshal(envelope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = registry.Envelope().Validate(encoded)
+		if err != nil {
+			t.Errorf("%s envelope: %v", envelope.Type, err)
+		}
+	}
+}
+
+func TestConsumersCanReadEveryPublishedEvent(t *testing.T) {
+	registry, err := schema.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, problem := range registry.CheckContracts() {
+		t.Error(problem)
+	}
+	for _, event := range sampleEvents() {
+		for _, problem := range registry.CheckPublish(event.EventType(), event.EventVersion()) {
+			t.Error(problem)
+		}
+	}
+}
+
+func TestEveryVersionIsKept(t *testing.T) {
+	registry, err := schema.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range sampleEvents() {
+		versions := registry.Versions(event.EventType())
+		for i, version := range versions {
+			if version != i+1 {
+				t.Errorf("%s: versions %v have a gap", event.EventType(), versions)
+				break
+			}
+		}
+		if len(versions) != event.EventVersion() {
+			t.Errorf("%s: published at v%d, registry has versions %v", event.EventType(), event.EventVersion(), versions)
+		}
+	}
+}
+
+func TestPublishedSchemasAreFrozen(t *testing.T) {
+	registry, err := schema.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, problem := range registry.CheckPublished() {
+		t.Error(problem)
+	}
+}
+
+func TestCheckPublishedCatchesEdits(t *testing.T) {
+	frozen := `{"type": "object", "required": ["order_id"], "additionalProperties": false, "properties": {"order_id": {"type": "integer"}}}`
+	tests := []struct {
+		name    string
+		event   string
+		problem string
+	}{
+		{"unchanged", frozen, ""},
+		{"tightened", strings.Replace(frozen, `{"type": "integer"}`, `{"type": "integer", "minimum": 1}`, 1), ""},
+		{"field added", strings.Replace(frozen, `{"type": "integer"}}`, `{"type": "integer"}, "note": {"type": "string"}}`, 1), "note is not allowed"},
+		{"type changed", strings.Replace(frozen, `"integer"`, `"string"`, 1), "$.order_id: may be string"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry, err := schema.Load(fstest.MapFS{
+				"envelope.json":               {Data: []byte(`{"type": "object"}`)},
+				"events/OrderPaid/v1.json":    {Data: []byte(test.event)},
+				"published/OrderPaid/v1.json": {Data: []byte(frozen)},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			problems := registry.CheckPublished()
+			if test.problem == "" {
+				if len(problems) > 0 {
+					t.Fatalf("unexpected problems: %v", problems)
+				}
+				return
+			}
+			if !strings.Contains(strings.Join(problems, "\n"), test.problem) {
+				t.Fatalf("got %v, want a problem about %q", problems, test.problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package schema
+
+import (
+	"embed"
+	"errors"
+	"eventschema"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownEvent is returned for an event type and version the registry has no schema for.
+var ErrUnknownEvent = errors.New("no schema registered for event")
+
+//go:embed registry
+var embedded embed.FS
+
+var loadDefault = sync.OnceValues(func() (*Registry, error) {
+	registry, err := fs.Sub(embedded, "registry")
+	if err != nil {
+		return nil, err
+	}
+	return Load(registry)
+})
+
+// Default returns the registry embedded in the binary, so events are checked without reaching
+// any schema registry service.
+func Default() (*Registry, error) {
+	return loadDefault()
+}
+
+// Validate checks the payload of an event against its schema in the default registry.
+func Validate(eventType string, version int, payload []byte) error {
+	registry, err := Default()
+	if err != nil {
+		return err
+	}
+	return registry.Validate(eventType, version, payload)
+}
+
+type schemaKey struct {
+	eventType string
+	version   int
+}
+
+// Contract is what a consumer reads from the payload of one version of an event type.
+type Contract struct {
+	Consumer  string
+	EventType string
+	Version   int
+	Schema    *eventschema.Schema
+}
+
+// Registry holds the schemas of the events published by the order service, a frozen copy of each
+// of them as it was first published, and the contracts of their consumers. Once a version of an
+// event has been published its schema may only change in ways that keep the frozen copy and every
+// contract on it satisfied; anything else needs a new version.
+type Registry struct {
+	envelope  *eventschema.Schema
+	events    map[schemaKey]*eventschema.Schema
+	published map[schemaKey]*eventschema.Schema
+	contracts []Contract
+}
+
+// Load reads a registry laid out as:
+//
+//	envelope.json                               the envelope around every event
+//	events/<type>/v<version>.json               the payload of a version of an event type
+//	published/<type>/v<version>.json            the payload schema as it was first published, never edited
+//	consumers/<consumer>/<type>/v<version>.json what a consumer reads from that payload
+//
+// The contract files are checked against the structs the consumers decode events into by the
+// tests of the consumer services.
+func Load(fsys fs.FS) (*Registry, error) {
+	registry := &Registry{
+		events:    map[schemaKey]*eventschema.Schema{},
+		published: map[schemaKey]*eventschema.Schema{},
+	}
+	var err error
+	registry.envelope, err = parseFile(fsys, "envelope.json")
+	if err != nil {
+		return nil, err
+	}
+
+	err = fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || name == "envelope.json" {
+			return err
+		}
+		parts := strings.Split(name, "/")
+		key, ok := parseSchemaKey(parts)
+		if !ok {
+			return fmt.Errorf("%s: not a schema of the registry layout", name)
+		}
+		schema, err := parseFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(parts) == 3 && parts[0] == "events":
+			registry.events[key] = schema
+		case len(parts) == 3 && parts[0] == "published":
+			registry.published[key] = schema
+		case len(parts) == 4 && parts[0] == "consumers":
+			registry.contracts = append(registry.contracts, Contract{
+				Consumer:  parts[1],
+				EventType: key.eventType,
+				Version:   key.version,
+				Schema:    schema,
+			})
+		default:
+			return fmt.Errorf("%s: not a schema of the registry layout", name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+func parseFile(fsys fs.FS, name string) (*eventschema.Schema, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := eventschema.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return schema, nil
+}
+
+// parseSchemaKey reads the event type and version from the last two elements of a schema path,
+// <type>/v<version>.json.
+func parseSchemaKey(parts []string) (schemaKey, bool) {
+	if len(parts) < 2 {
+		return schemaKey{}, false
+	}
+	file := parts[len(parts)-1]
+	if path.Ext(file) != ".json" || !strings.HasPrefix(file, "v") {
+		return schemaKey{}, false
+	}
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file, "v"), ".json"))
+	if err != nil || version < 1 {
+		return schemaKey{}, false
+	}
+	return schemaKey{eventType: parts[len(parts)-2], version: version}, true
+}
+
+func (r *Registry) Envelope() *eventschema.Schema {
+	return r.envelope
+}
+
+func (r *Registry) Schema(eventType string, version int) (*eventschema.Schema, error) {
+	schema, ok := r.events[schemaKey{eventType: eventType, version: version}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnknownEvent, eventType, version)
+	}
+	return schema, nil
+}
+
+// Validate checks the payload of an event against the schema of its type and version.
+func (r *Registry) Validate(eventType string, version int, payload []byte) error {
+	schema, err := r.Schema(eventType, version)
+	if err != nil {
+		return err
+	}
+	err = schema.Validate(payload)
+	if err != nil {
+		return fmt.Errorf("%s v%d: %w", eventType, version, err)
+	}
+	return nil
+}
+
+// Versions lists the versions of an event type in the registry, oldest first.
+func (r *Registry) Versions(eventType string) []int {
+	var versions []int
+	for key := range r.events {
+		if key.eventType == eventType {
+			versions = append(versions, key.version)
+		}
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+func (r *Registry) Contracts() []Contract {
+	return r.contracts
+}
+
+// CheckContracts reports the contracts the event schemas no longer satisfy, and those on an event
+// version the registry doesn't have.
+func (r *Registry) CheckContracts() []string {
+	var problems []string
+	for _, contract := range r.contracts {
+		schema, err := r.Schema(contract.EventType, contract.Version)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", contract.Consumer, err))
+			continue
+		}
+		for _, problem := range eventschema.CheckCompatible(schema, contract.Schema) {
+			problems = append(problems, fmt.Sprintf("%s reading %s v%d: %s", contract.Consumer, contract.EventType, contract.Version, problem))
+		}
+	}
+	return problems
+}
+
+// CheckPublished reports the event schemas changed since they were published in ways their frozen
+// copy doesn't allow, those removed, and those without a frozen copy yet.
+func (r *Registry) CheckPublished() []string {
+	var problems []string
+	for _, key := range sortedSchemaKeys(r.events) {
+		if _, ok := r.published[key]; !ok {
+			problems = append(problems, fmt.Sprintf("%s v%d has no frozen copy in published", key.eventType, key.version))
+		}
+	}
+	for _, key := range sortedSchemaKeys(r.published) {
+		schema, ok := r.events[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s v%d was published but its schema was removed", key.eventType, key.version))
+			continue
+		}
+		for _, problem := range eventschema.CheckCompatible(schema, r.published[key]) {
+			problems = append(problems, fmt.Sprintf("%s v%d changed since it was published: %s", key.eventType, key.version, problem))
+		}
+	}
+	return problems
+}
+
+func sortedSchemaKeys(schemas map[schemaKey]*eventschema.Schema) []schemaKey {
+	keys := make([]schemaKey, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].eventType != keys[j].eventType {
+			return keys[i].eventType < keys[j].eventType
+		}
+		return keys[i].version < keys[j].version
+	})
+	return keys
+}
+
+// CheckPublish reports the consumers of eventType that couldn't read it if it were published at
+// version: those without a contract on that version.
+func (r *Registry) CheckPublish(eventType string, version int) []string {
+	var problems []string
+	if _, err := r.Schema(eventType, version); err != nil {
+		problems = append(problems, err.Error())
+	}
+	readable := map[string]bool{}
+	var consumers []string
+	for _, contract := range r.contracts {
+		if contract.EventType != eventType {
+			continue
+		}
+		if _, ok := readable[contract.Consumer]; !ok {
+			consumers = append(consumers, contract.Consumer)
+		}
+		readable[contract.Consumer] = readable[contract.Consumer] || contract.Version == version
+	}
+	sort.Strings(consumers)
+	for _, consumer := range consumers {
+		if !readable[consumer] {
+			problems = append(problems, fmt.Sprintf("%s has no contract on %s v%d", consumer, eventType, version))
+		}
+	}
+	return problems
+}
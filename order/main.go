@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"order/order/cmd/order/handler"
 	"order/order/cmd/order/repository"
 	"order/order/cmd/order/resource"
 	"order/order/cmd/order/service"
 	"order/order/cmd/order/usecase"
+	"order/order/cmd/order/worker"
 	"order/order/config"
 	"order/order/infrastructure/log"
+	"order/order/infrastructure/productclient"
+	"order/order/infrastructure/userclient"
 	"order/order/kafka"
 	routes "order/order/router"
 
@@ -18,20 +22,58 @@ func main() {
 	cfg := config.LoadConfig()
 	db := resource.InitDB(&cfg)
 	redis := resource.InitRedis(&cfg)
-	KafkaProducer := kafka.NewKafkaProducer([]string{"localhost:9093"}, "order.created")
+	KafkaProducer := kafka.NewKafkaProducer(cfg.Kafka.Brokers)
 	defer KafkaProducer.Close()
 
+	log.SetupLoger()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	port := cfg.App.Port
 	router := gin.Default()
-	orderRepository := repository.NewOrderRepository(db, redis, cfg.Product.Host)
+	productClient := productclient.NewClient(productclient.Config{
+		Host:                    cfg.Product.Host,
+		JWTSecret:               cfg.Secret.JWTSecret,
+		Timeout:                 cfg.Product.Timeout,
+		MaxRetries:              cfg.Product.MaxRetries,
+		RetryBaseDelay:          cfg.Product.RetryBaseDelay,
+		RetryMaxDelay:           cfg.Product.RetryMaxDelay,
+		BreakerFailureThreshold: cfg.Product.BreakerFailureThreshold,
+		BreakerOpenTimeout:      cfg.Product.BreakerOpenTimeout,
+	})
+	userClient := userclient.NewClient(userclient.Config{
+		Host:    cfg.User.Host,
+		Timeout: cfg.User.Timeout,
+	})
+	orderRepository := repository.NewOrderRepository(db, redis, productClient, userClient)
 	orderService := service.NewOrderService(orderRepository)
-	orderUseCase := usecase.NewOrderUseCase(orderService, *KafkaProducer)
+	orderUseCase := usecase.NewOrderUseCase(orderService, cfg.Checkout.IdempotencyTTL, cfg.Tax.Mode)
 	orderHandler := handler.NewOrderHandler(orderUseCase)
-	routes.SetupRouter(router, *orderHandler, cfg.Secret.JWTSecret)
+
+	outboxRelay := worker.NewOutboxRelay(orderRepository, KafkaProducer, cfg.Outbox)
+	go outboxRelay.Start(ctx)
+	outboxHandler := handler.NewOutboxHandler(outboxRelay)
+
+	kafkaConsumer := kafka.NewKafkaConsumer(kafka.ConsumerConfig{
+		Brokers:        cfg.Kafka.Brokers,
+		GroupID:        cfg.Kafka.ConsumerGroup,
+		InitialBackoff: cfg.Kafka.Consumer.InitialBackoff,
+		MaxBackoff:     cfg.Kafka.Consumer.MaxBackoff,
+	}, KafkaProducer)
+	kafkaConsumer.Register(kafka.TopicPaymentSucceeded, orderHandler.HandlePaymentSucceeded)
+	kafkaConsumer.Register(kafka.TopicPaymentFailed, orderHandler.HandlePaymentFailed)
+	kafkaConsumer.Register(kafka.TopicPaymentCancelled, orderHandler.HandlePaymentCancelled)
+	kafkaConsumer.Register(kafka.TopicShipmentUpdated, orderHandler.HandleShipmentUpdated)
+	kafkaConsumer.Register(kafka.TopicPaymentRefunded, orderHandler.HandlePaymentRefunded)
+	kafkaConsumer.Register(kafka.TopicPaymentRefundFailed, orderHandler.HandlePaymentRefundFailed)
+	kafkaConsumer.Start(ctx)
+	defer kafkaConsumer.Close()
+
+	routes.SetupRouter(router, *orderHandler, *outboxHandler, cfg.Secret.JWTSecret)
 
 	println("Starting server on port " + port)
 
-	log.SetupLoger()
 	router.Run(":" + port)
 	println("Starting server on port:", port)
 
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+	constant "order/order/infrastructure/constans"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminOnly lets through only users whose token carries the admin role. It must run after
+// AuthMiddleware.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != constant.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "forbidden",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,2 @@
+-- stock reservation held in the product service for the order
+alter table orders add column reservation_id varchar(64);
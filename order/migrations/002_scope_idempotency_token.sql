@@ -0,0 +1,11 @@
+-- idempotency tokens are claimed in the order transaction, scoped per user and expire
+-- old rows were never linked to an order, so they can't be replayed and are dropped
+delete from order_request_log;
+
+alter table order_request_log rename column crate_time to create_time;
+alter table order_request_log drop constraint order_request_log_idempotency_token_key;
+alter table order_request_log
+    add column user_id bigint not null,
+    add column order_id bigint not null references orders(id),
+    add column expire_time timestamp not null,
+    add constraint uq_order_request_log_user_token unique (user_id, idempotency_token);
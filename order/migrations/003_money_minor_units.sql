@@ -0,0 +1,19 @@
+-- amounts move from numeric major units to integer minor units plus a currency code.
+-- every existing order is in USD, which has two decimal places.
+alter table orders alter column amount type bigint using round(amount * 100);
+alter table orders add column currency char(3) not null default 'USD';
+
+-- line items keep their price as {"amount": <minor units>, "currency": "USD"}
+update order_detail od
+set products = (
+    select coalesce(jsonb_agg(
+        case
+            when jsonb_typeof(item -> 'price') = 'number' then
+                jsonb_set(item, '{price}', jsonb_build_object(
+                    'amount', round((item ->> 'price')::numeric * 100)::bigint,
+                    'currency', 'USD'))
+            else item
+        end
+        order by ord), '[]'::jsonb)::text
+    from jsonb_array_elements(od.products::jsonb) with ordinality as items(item, ord)
+);
@@ -0,0 +1,3 @@
+-- rate from the product base currency to the currency the order was charged in,
+-- orders placed so far were charged in the base currency
+alter table orders add column exchange_rate numeric(20, 10) not null default 1;
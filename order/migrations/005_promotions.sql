@@ -0,0 +1,53 @@
+-- coupons redeemable at checkout and their redemptions
+create table promotion(
+    id bigserial primary key,
+    code varchar(50) not null,
+    name varchar(255) not null,
+    type varchar(20) not null check (type in ('percentage', 'fixed_amount', 'buy_x_get_y')),
+    discount_bps bigint not null default 0,
+    discount_amount bigint not null default 0,
+    currency char(3) not null,
+    category_id integer,
+    buy_qty integer not null default 0,
+    get_qty integer not null default 0,
+    min_spend_amount bigint not null default 0,
+    usage_limit integer not null default 0,
+    usage_limit_per_user integer not null default 0,
+    usage_count integer not null default 0,
+    stackable boolean not null default false,
+    active boolean not null default true,
+    start_time timestamp not null default current_timestamp,
+    end_time timestamp,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create unique index uq_promotion_code on promotion (upper(code));
+
+create table promotion_usage(
+    id bigserial primary key,
+    promotion_id bigint not null references promotion(id),
+    user_id bigint not null,
+    order_id bigint not null references orders(id),
+    discount_amount bigint not null,
+    discount_currency char(3) not null,
+    create_time timestamp default current_timestamp
+);
+
+create index idx_promotion_usage_user on promotion_usage (promotion_id, user_id);
+
+-- orders keep their subtotal and discount, existing orders had no discount
+alter table orders
+    add column subtotal_amount bigint,
+    add column subtotal_currency char(3),
+    add column discount_amount bigint not null default 0,
+    add column discount_currency char(3);
+
+update orders set subtotal_amount = amount, subtotal_currency = currency, discount_currency = currency;
+
+alter table orders
+    alter column subtotal_amount set not null,
+    alter column subtotal_currency set not null,
+    alter column discount_currency set not null;
+
+alter table order_detail add column discounts text not null default '[]';
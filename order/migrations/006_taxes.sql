@@ -0,0 +1,26 @@
+-- tax rates per region, optionally per product category
+create table tax_rate(
+    id bigserial primary key,
+    region varchar(20) not null,
+    category_id integer,
+    name varchar(100) not null,
+    rate_bps bigint not null check (rate_bps >= 0),
+    active boolean not null default true,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create unique index uq_tax_rate_region_category on tax_rate (region, coalesce(category_id, 0)) where active;
+
+-- orders keep their tax and how it was charged, existing orders weren't taxed
+alter table orders
+    add column tax_amount bigint not null default 0,
+    add column tax_currency char(3),
+    add column tax_mode varchar(20) not null default 'exclusive',
+    add column shipping_region varchar(20);
+
+update orders set tax_currency = currency;
+
+alter table orders alter column tax_currency set not null;
+
+alter table order_detail add column taxes text not null default '[]';
@@ -0,0 +1,4 @@
+-- orders shipped to an address book entry keep a copy of it, existing orders only have the free text address
+alter table orders
+    add column shipping_address_id bigint,
+    add column shipping_address_detail text not null default '';
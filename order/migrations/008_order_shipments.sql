@@ -0,0 +1,13 @@
+-- latest tracking of every parcel, from the fulfillment service's shipment.updated events
+create table order_shipment(
+    shipment_id bigint primary key,
+    order_id bigint not null references orders(id),
+    parcel_no integer not null,
+    parcel_count integer not null,
+    carrier varchar(50),
+    tracking_number varchar(100),
+    status varchar(20) not null,
+    update_time timestamp default current_timestamp
+);
+
+create index idx_order_shipment_order on order_shipment (order_id, parcel_no);
@@ -0,0 +1,18 @@
+-- returns of completed orders, each step is also recorded in the order history
+create table order_return(
+    id bigserial primary key,
+    order_id bigint not null references orders(id),
+    user_id bigint not null,
+    status varchar(20) not null,
+    reason text not null,
+    items text not null,
+    refund_amount bigint not null,
+    refund_currency char(3) not null,
+    restock boolean not null default false,
+    admin_note text not null default '',
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create index idx_order_return_order on order_return (order_id);
+create index idx_order_return_status on order_return (status, id);
@@ -0,0 +1,4 @@
+-- keyset pagination of the order history, by order ID or by amount, and its date range filter
+create index idx_orders_user_id on orders (user_id, id);
+create index idx_orders_user_amount on orders (user_id, amount, id);
+create index idx_orders_user_create_time on orders (user_id, create_time);
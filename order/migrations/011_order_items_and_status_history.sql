@@ -0,0 +1,74 @@
+-- line items and the status timeline move out of the JSON columns of order_detail into their own
+-- tables, so they can be queried and reported on in SQL.
+create table order_item(
+    id bigserial primary key,
+    order_id bigint not null references orders(id),
+    line_no integer not null,
+    product_id bigint not null,
+    quantity integer not null check (quantity > 0),
+    currency char(3) not null,
+    price_amount bigint not null,
+    base_price_amount bigint,
+    base_currency char(3),
+    exchange_rate varchar(30) not null default '',
+    discount_amount bigint not null default 0,
+    discounts text not null default '[]',
+    tax_name varchar(100),
+    tax_rate_bps bigint not null default 0,
+    tax_taxable_amount bigint not null default 0,
+    tax_amount bigint not null default 0,
+    constraint uq_order_item_line unique (order_id, line_no)
+);
+
+create index idx_order_item_product on order_item (product_id, order_id);
+
+create table order_status_history(
+    id bigserial primary key,
+    order_id bigint not null references orders(id),
+    status varchar(50) not null,
+    return_id bigint,
+    note text not null default '',
+    create_time timestamp not null default current_timestamp
+);
+
+create index idx_order_status_history_order on order_status_history (order_id, id);
+
+-- backfill the items, keeping their position in the order as line_no. Prices were already
+-- converted to {"amount": <minor units>, "currency": ...} by 003_money_minor_units.
+insert into order_item (order_id, line_no, product_id, quantity, currency, price_amount,
+                        base_price_amount, base_currency, exchange_rate, discount_amount, discounts,
+                        tax_name, tax_rate_bps, tax_taxable_amount, tax_amount)
+select o.id,
+       items.ord,
+       (items.item ->> 'product_id')::bigint,
+       (items.item ->> 'quantity')::integer,
+       coalesce(items.item -> 'price' ->> 'currency', o.currency),
+       (items.item -> 'price' ->> 'amount')::bigint,
+       (items.item -> 'base_price' ->> 'amount')::bigint,
+       items.item -> 'base_price' ->> 'currency',
+       coalesce(items.item ->> 'exchange_rate', ''),
+       coalesce((select sum((discount -> 'amount' ->> 'amount')::bigint)
+                 from jsonb_array_elements(coalesce(items.item -> 'discounts', '[]'::jsonb)) as discount), 0),
+       coalesce(items.item -> 'discounts', '[]'::jsonb)::text,
+       items.item -> 'tax' ->> 'name',
+       coalesce((items.item -> 'tax' ->> 'rate_bps')::bigint, 0),
+       coalesce((items.item -> 'tax' -> 'taxable' ->> 'amount')::bigint, 0),
+       coalesce((items.item -> 'tax' -> 'amount' ->> 'amount')::bigint, 0)
+from orders o
+join order_detail od on od.id = o.order_detail_id
+cross join lateral jsonb_array_elements(od.products::jsonb) with ordinality as items(item, ord);
+
+-- backfill the timeline in its original order; entries without a timestamp take the order's
+insert into order_status_history (order_id, status, return_id, note, create_time)
+select o.id,
+       history.entry ->> 'status',
+       nullif(history.entry ->> 'return_id', '')::bigint,
+       coalesce(history.entry ->> 'note', ''),
+       coalesce((history.entry ->> 'timestamp')::timestamptz, o.create_time)
+from orders o
+join order_detail od on od.id = o.order_detail_id
+cross join lateral jsonb_array_elements(od.order_history::jsonb) with ordinality as history(entry, ord)
+order by o.id, history.ord;
+
+alter table order_detail drop column products;
+alter table order_detail drop column order_history;
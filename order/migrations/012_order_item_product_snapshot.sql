@@ -0,0 +1,8 @@
+-- the product as it was sold, taken at checkout. Lines of earlier orders have no snapshot and keep
+-- these columns null.
+alter table order_item add column product_sku varchar(64);
+alter table order_item add column product_name varchar(255);
+alter table order_item add column product_description text;
+alter table order_item add column product_category_id integer;
+alter table order_item add column product_price_amount bigint;
+alter table order_item add column product_price_currency char(3);
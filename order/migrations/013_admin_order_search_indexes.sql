@@ -0,0 +1,3 @@
+-- the admin order search sorts and filters the orders of all users
+create index idx_orders_amount on orders (amount, id);
+create index idx_orders_create_time on orders (create_time);
@@ -0,0 +1,3 @@
+-- the category name of the product as it was sold; categories can be deleted, the id alone doesn't
+-- say what was bought. Lines snapshotted before keep it null.
+alter table order_item add column product_category varchar(255);
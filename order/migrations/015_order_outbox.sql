@@ -0,0 +1,16 @@
+-- events written in the transaction of the change they announce and sent to kafka by the relay
+create table order_outbox(
+    id bigserial primary key,
+    aggregate_id bigint not null,
+    topic varchar(100) not null,
+    event_key varchar(100) not null,
+    payload text not null,
+    status varchar(20) not null,
+    attempts integer not null default 0,
+    last_error text,
+    next_attempt_time timestamp not null default current_timestamp,
+    create_time timestamp default current_timestamp,
+    sent_time timestamp
+);
+
+create index idx_order_outbox_pending on order_outbox (status, next_attempt_time, id);
@@ -0,0 +1,10 @@
+-- latest payment of every order, from the payment service's payment events
+create table order_payment(
+    order_id bigint primary key references orders(id),
+    payment_id bigint not null,
+    status varchar(20) not null,
+    provider varchar(50),
+    provider_ref varchar(100),
+    failure_reason text,
+    update_time timestamp default current_timestamp
+);
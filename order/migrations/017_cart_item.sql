@@ -0,0 +1,10 @@
+-- items in the cart of every user, one row per product
+create table cart_item(
+    id bigserial primary key,
+    user_id bigint not null,
+    product_id bigint not null,
+    quantity integer not null,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp,
+    constraint uq_cart_item_user_product unique (user_id, product_id)
+);
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"strings"
+)
+
+// Address is a shipping address from the user's address book. Orders keep a copy of it, so later
+// changes to the address book don't change where an order ships to.
+type Address struct {
+	ID         int64  `json:"id"`
+	Label      string `json:"label,omitempty"`
+	Recipient  string `json:"recipient"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone"`
+	IsDefault  bool   `json:"is_default,omitempty"`
+}
+
+type GetAddressResponse struct {
+	Address Address `json:"address"`
+}
+
+type GetAddressesResponse struct {
+	Addresses []Address `json:"addresses"`
+}
+
+// String formats the address on one line, e.g. "Budi, Jl. Sudirman 1, Jakarta, JK 10220, ID".
+func (a Address) String() string {
+	parts := []string{a.Recipient, a.Line1, a.Line2, a.City, strings.TrimSpace(a.Region + " " + a.PostalCode), a.Country}
+	nonEmpty := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
+
+// TaxRegion returns the ISO 3166-2 code of the address, e.g. "ID-JK", or the country code when the
+// address has no region.
+func (a Address) TaxRegion() string {
+	if a.Region == "" {
+		return a.Country
+	}
+	return a.Country + "-" + a.Region
+}
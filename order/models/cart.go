@@ -0,0 +1,50 @@
+package models
+
+import (
+	"money"
+	"time"
+)
+
+type CartItem struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	ProductID  int64     `json:"product_id"`
+	Quantity   int       `json:"quantity"`
+	CreateTime time.Time `json:"create_time"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+type AddCartItemRequest struct {
+	ProductID int64 `json:"product_id" binding:"required"`
+	Quantity  int   `json:"quantity" binding:"required"`
+}
+
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+type CheckoutCartRequest struct {
+	PaymentMethod     string   `json:"payment_method"`
+	ShippingAddress   string   `json:"shipping_address"`
+	ShippingAddressID int64    `json:"shipping_address_id"`
+	IdempotencyToken  string   `json:"idempotency_token"`
+	Currency          string   `json:"currency"`
+	CouponCodes       []string `json:"coupon_codes"`
+}
+
+// CartLine is a cart item priced with the product service's current price and stock.
+type CartLine struct {
+	ProductID int64       `json:"product_id"`
+	Name      string      `json:"name"`
+	Price     money.Money `json:"price"`
+	Quantity  int         `json:"quantity"`
+	Subtotal  money.Money `json:"subtotal"`
+	Stock     int         `json:"stock"`
+	Available bool        `json:"available"`
+}
+
+type CartResponse struct {
+	Items       []CartLine  `json:"items"`
+	TotalQty    int         `json:"total_qty"`
+	TotalAmount money.Money `json:"total_amount"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "order/order/kafka"
+
+// The events published by the order service, see kafka.Event.
+
+func (e OrderCreatedEvent) EventType() string   { return kafka.EventTypeOrderCreated }
+func (e OrderCreatedEvent) EventVersion() int   { return 1 }
+func (e OrderCreatedEvent) EventOrderID() int64 { return e.OrderID }
+
+func (e OrderCancelledEvent) EventType() string   { return kafka.EventTypeOrderCancelled }
+func (e OrderCancelledEvent) EventVersion() int   { return 1 }
+func (e OrderCancelledEvent) EventOrderID() int64 { return e.OrderID }
+
+func (e OrderStatusChangedEvent) EventType() string   { return kafka.EventTypeOrderStatusChanged }
+func (e OrderStatusChangedEvent) EventVersion() int   { return 1 }
+func (e OrderStatusChangedEvent) EventOrderID() int64 { return e.OrderID }
+
+func (e OrderRefundedEvent) EventType() string   { return kafka.EventTypeOrderRefunded }
+func (e OrderRefundedEvent) EventVersion() int   { return 1 }
+func (e OrderRefundedEvent) EventOrderID() int64 { return e.OrderID }
+
+func (e ReturnEvent) EventType() string   { return kafka.EventTypeReturnUpdated }
+func (e ReturnEvent) EventVersion() int   { return 1 }
+func (e ReturnEvent) EventOrderID() int64 { return e.OrderID }
+
+func (e RefundRequestedEvent) EventType() string   { return kafka.EventTypeRefundRequested }
+func (e RefundRequestedEvent) EventVersion() int   { return 1 }
+func (e RefundRequestedEvent) EventOrderID() int64 { return e.OrderID }
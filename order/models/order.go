@@ -1,79 +1,290 @@
 package models
 
-import "time"
+import (
+	"money"
+	"time"
+)
 
 type Order struct {
 	ID              int64
 	UserID          int64
 	OrderDetailID   int64
-	Amount          float64
+	Subtotal        money.Money `gorm:"embedded;embeddedPrefix:subtotal_"`
+	Discount        money.Money `gorm:"embedded;embeddedPrefix:discount_"`
+	Tax             money.Money `gorm:"embedded;embeddedPrefix:tax_"`
+	TaxMode         string
+	Amount          money.Money `gorm:"embedded"`
 	TotalQty        int
 	Status          int
 	PaymentMethod   string
 	ShippingAddress string
+	ShippingRegion  string
+	// ShippingAddressID and ShippingAddressDetail, the JSON copy of the address, are set when the
+	// order ships to an address book entry
+	ShippingAddressID     int64
+	ShippingAddressDetail string
+	ReservationID         string
+	// ExchangeRate is the rate from the product base currency to the currency the order was charged in
+	ExchangeRate string
 }
 
+// OrderDetail holds the order-wide promotions and tax breakdown; the lines of an order are its
+// OrderItems and its status timeline its OrderStatusHistory.
 type OrderDetail struct {
-	ID           int64
-	Products     string
-	OrderHistory string
+	ID        int64
+	Discounts string
+	Taxes     string
 }
 
 type CheckOutItem struct {
-	ProductID int64   `json:"product_id"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
+	ProductID int64       `json:"product_id"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
+
+	// BasePrice and ExchangeRate are locked in at checkout when Price was converted
+	BasePrice    *money.Money `json:"base_price,omitempty"`
+	ExchangeRate string       `json:"exchange_rate,omitempty"`
+	// Discounts is the breakdown of the promotions taken off this line
+	Discounts []LineDiscount `json:"discounts,omitempty"`
+	// Tax is the tax charged on this line after discounts
+	Tax *TaxBreakdown `json:"tax,omitempty"`
+	// Product is the product as it was sold, taken at checkout
+	Product *ProductSnapshot `json:"product,omitempty"`
+}
+
+// ProductSnapshot keeps what was bought readable after the product or its category is renamed or
+// deleted. Price is the list price in the product's own currency.
+type ProductSnapshot struct {
+	SKU         string      `json:"sku"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	CategoryID  int         `json:"category_id"`
+	Category    string      `json:"category"`
+	Price       money.Money `json:"price"`
 }
 type CheckOutRequest struct {
-	UserID           int64          `json:"user_id"`
-	Items            []CheckOutItem `json:"items"`
-	PaymentMethod    string         `json:"payment_method"`
-	ShippingAddress  string         `json:"shipping_address"`
-	IdempotencyToken string         `json:"idempotency_token"`
+	UserID        int64          `json:"user_id"`
+	Items         []CheckOutItem `json:"items"`
+	Currency      string         `json:"currency"`
+	CouponCodes   []string       `json:"coupon_codes"`
+	PaymentMethod string         `json:"payment_method"`
+	// ShippingAddress is filled in from the address book; a free text address is refused because
+	// the tax region can't be told from it
+	ShippingAddress string `json:"shipping_address"`
+	// ShippingRegion is the region of the shipping address, e.g. "ID-JK", which decides the tax
+	// rates. It always comes from the address book, never from the request.
+	ShippingRegion string `json:"-"`
+	// ShippingAddressID picks an address of the user's address book; when it isn't set the order
+	// ships to the default address
+	ShippingAddressID int64  `json:"shipping_address_id"`
+	IdempotencyToken  string `json:"idempotency_token"`
+	// Authorization is the user's authorization header, used to read the address book
+	Authorization string `json:"-"`
+}
+
+type UpdateOrderStatusParam struct {
+	OrderID int64
+	UserID  int64
+	Status  int
+}
+
+// ForceOrderStatusRequest moves an order to Status bypassing the state machine. The reason is kept
+// in the order history.
+type ForceOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+type ForceOrderStatusParam struct {
+	OrderID int64
+	AdminID int64
+	Status  int
+	Reason  string
 }
 
+// OrderHistoryRequest holds the query string of the order history. Status is a comma separated list
+// of status names or codes, From and To are RFC 3339 times or dates, To being inclusive, and
+// MinAmount and MaxAmount are in minor units of Currency, which they and the amount sorts require.
+type OrderHistoryRequest struct {
+	Status    string `form:"status"`
+	From      string `form:"from"`
+	To        string `form:"to"`
+	MinAmount *int64 `form:"min_amount"`
+	MaxAmount *int64 `form:"max_amount"`
+	Currency  string `form:"currency"`
+	ProductID int64  `form:"product_id"`
+	Sort      string `form:"sort"`
+	Limit     int    `form:"limit"`
+	Cursor    string `form:"cursor"`
+}
+
+// AdminOrderSearchRequest holds the query string of the admin order search, the order history
+// filters over the orders of every user or of UserID when it is set.
+type AdminOrderSearchRequest struct {
+	OrderHistoryRequest
+	UserID int64 `form:"user_id"`
+}
+
+// OrderHistoryParam filters the orders of UserID, of all users when it is zero. Empty or nil fields
+// don't filter.
 type OrderHistoryParam struct {
-	UserID int64
-	Status int
+	UserID    int64
+	Statuses  []int
+	From      *time.Time
+	To        *time.Time
+	MinAmount *int64
+	MaxAmount *int64
+	Currency  string
+	ProductID int64
+	Sort      string
+	Limit     int
+	// Cursor is the position of the last order of the previous page
+	Cursor *OrderHistoryCursor
+}
+
+// OrderHistoryCursor is the position of an order in the order history, encoded in the next_cursor
+// of a page. Amount is only set when sorting by amount.
+type OrderHistoryCursor struct {
+	Sort   string `json:"sort"`
+	ID     int64  `json:"id"`
+	Amount int64  `json:"amount,omitempty"`
+}
+
+type OrderHistoryPage struct {
+	Orders []OrderHistoryResponse
+	// NextCursor is empty on the last page
+	NextCursor string
 }
 
 type OrderHistoryResponse struct {
-	OrderID         int64           `json:"order_id"`
-	TotalAmount     float64         `json:"total_amount"`
-	TotalQty        int             `json:"total_qty"`
-	Status          string          `json:"status"`
-	PaymentMethod   string          `json:"payment_method"`
-	ShippingAddress string          `json:"shipping_address"`
-	Products        []CheckOutItem  `json:"products"`
-	History         []Statushistory `json:"history"`
+	OrderID               int64              `json:"order_id"`
+	UserID                int64              `json:"user_id"`
+	Subtotal              money.Money        `json:"subtotal"`
+	Discount              money.Money        `json:"discount"`
+	Promotions            []AppliedPromotion `json:"promotions"`
+	Tax                   money.Money        `json:"tax"`
+	TaxMode               string             `json:"tax_mode"`
+	Taxes                 []TaxBreakdown     `json:"taxes"`
+	TotalAmount           money.Money        `json:"total_amount"`
+	ExchangeRate          string             `json:"exchange_rate"`
+	TotalQty              int                `json:"total_qty"`
+	Status                string             `json:"status"`
+	PaymentMethod         string             `json:"payment_method"`
+	ShippingAddress       string             `json:"shipping_address"`
+	ShippingRegion        string             `json:"shipping_region"`
+	ShippingAddressDetail *Address           `json:"shipping_address_detail,omitempty"`
+	Shipments             []OrderShipment    `json:"shipments"`
+	Products              []CheckOutItem     `json:"products"`
+	History               []Statushistory    `json:"history"`
+	CreateTime            time.Time          `json:"create_time"`
 }
 
 type Statushistory struct {
 	Status    string `json:"status"`
 	Timestamp string `json:"timestamp"`
+	// ReturnID and Note are set on the entries of a return, e.g. "return_approved"
+	ReturnID int64  `json:"return_id,omitempty"`
+	Note     string `json:"note,omitempty"`
 }
 
 type OrderRequestLog struct {
 	ID               int64     `json:"id"`
+	UserID           int64     `json:"user_id"`
 	IdempotencyToken string    `json:"idempotancy_token"`
+	OrderID          int64     `json:"order_id"`
 	CreateTime       time.Time `json:"create_time"`
+	ExpireTime       time.Time `json:"expire_time"`
 }
 
 type OrderHistoryResult struct {
-	ID              int64 `gorm:"column:id"`
-	Amount          float64
-	TotalQty        int
-	Status          int
-	PaymentMethod   string
-	ShippingAddress string
-	Products        string `gorm:"column:products"`
-	OrderHistory    string `gorm:"column:order_history"`
+	ID                    int64       `gorm:"column:id"`
+	UserID                int64       `gorm:"column:user_id"`
+	Subtotal              money.Money `gorm:"embedded;embeddedPrefix:subtotal_"`
+	Discount              money.Money `gorm:"embedded;embeddedPrefix:discount_"`
+	Tax                   money.Money `gorm:"embedded;embeddedPrefix:tax_"`
+	TaxMode               string
+	Amount                money.Money `gorm:"embedded"`
+	ExchangeRate          string
+	TotalQty              int
+	Status                int
+	PaymentMethod         string
+	ShippingAddress       string
+	ShippingRegion        string
+	ShippingAddressDetail string
+	Discounts             string `gorm:"column:discounts"`
+	Taxes                 string `gorm:"column:taxes"`
+	CreateTime            time.Time
 }
 
 type OrderCreatedEvent struct {
-	OrderID         int64   `json:"order_id"`
-	UserID          int64   `json:"user_id"`
-	TotalAmount     float64 `json:"total_amount"`
-	PaymentMethod   string  `json:"payment_method"`
-	ShippingAddress string  `json:"shipping_address"`
+	OrderID         int64       `json:"order_id"`
+	UserID          int64       `json:"user_id"`
+	TotalAmount     money.Money `json:"total_amount"`
+	PaymentMethod   string      `json:"payment_method"`
+	ShippingAddress string      `json:"shipping_address"`
+	// Items are the products to ship
+	Items []OrderEventItem `json:"items"`
+}
+
+type OrderEventItem struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+type OrderCancelledEvent struct {
+	OrderID     int64       `json:"order_id"`
+	UserID      int64       `json:"user_id"`
+	TotalAmount money.Money `json:"total_amount"`
+	CancelledAt string      `json:"cancelled_at"`
+}
+
+// OrderStatusChangedEvent is published every time an order moves to another status. Forced is set
+// when an admin moved it outside the state machine, Reason being theirs.
+type OrderStatusChangedEvent struct {
+	OrderID    int64  `json:"order_id"`
+	UserID     int64  `json:"user_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	Forced     bool   `json:"forced,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	ChangedAt  string `json:"changed_at"`
+}
+
+// PaymentEvent is published by the payment service on payment.succeeded and payment.failed.
+type PaymentEvent struct {
+	PaymentID     int64       `json:"payment_id"`
+	OrderID       int64       `json:"order_id"`
+	UserID        int64       `json:"user_id"`
+	Amount        money.Money `json:"amount"`
+	Status        string      `json:"status"`
+	Provider      string      `json:"provider"`
+	ProviderRef   string      `json:"provider_ref"`
+	FailureReason string      `json:"failure_reason,omitempty"`
+	OccurredAt    string      `json:"occurred_at"`
+}
+
+type OrderPayment struct {
+	OrderID       int64     `json:"-"`
+	PaymentID     int64     `json:"payment_id"`
+	Status        string    `json:"status"`
+	Provider      string    `json:"provider"`
+	ProviderRef   string    `json:"provider_ref"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	UpdateTime    time.Time `json:"update_time"`
+}
+
+// OrderItemResponse is an order line with the product's current name.
+type OrderItemResponse struct {
+	CheckOutItem
+	Name string `json:"name"`
+}
+
+// OrderDetailResponse is the full view of a single order. Its Products, enriched with the product
+// names, replace those of the embedded OrderHistoryResponse.
+type OrderDetailResponse struct {
+	OrderHistoryResponse
+	Products []OrderItemResponse `json:"products"`
+	// Payment is nil until the payment service reports on the order
+	Payment *OrderPayment    `json:"payment"`
+	Returns []ReturnResponse `json:"returns"`
 }
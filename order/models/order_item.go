@@ -0,0 +1,155 @@
+package models
+
+import (
+	"encoding/json"
+	"money"
+	"time"
+)
+
+// OrderItem is a line of an order. All its amounts are in minor units of Currency, the currency the
+// order was charged in; BasePriceAmount and BaseCurrency are set when the price was converted and
+// TaxName when the line was taxed. Discounts holds the JSON encoded []LineDiscount. The Product
+// columns are the snapshot of the product, set for orders placed since snapshots are taken.
+type OrderItem struct {
+	ID               int64
+	OrderID          int64
+	LineNo           int
+	ProductID        int64
+	Quantity         int
+	Currency         string
+	PriceAmount      int64
+	BasePriceAmount  *int64
+	BaseCurrency     *string
+	ExchangeRate     string
+	DiscountAmount   int64
+	Discounts        string
+	TaxName          *string
+	TaxRateBps       int64
+	TaxTaxableAmount int64
+	TaxAmount        int64
+
+	ProductSKU           *string
+	ProductName          *string
+	ProductDescription   *string
+	ProductCategoryID    *int
+	ProductCategory      *string
+	ProductPriceAmount   *int64
+	ProductPriceCurrency *string
+}
+
+// OrderStatusHistory is an entry of the status timeline of an order.
+type OrderStatusHistory struct {
+	ID         int64
+	OrderID    int64
+	Status     string
+	ReturnID   *int64
+	Note       string
+	CreateTime time.Time
+}
+
+// NewOrderItem converts the checked out item on line lineNo of an order to its row.
+func NewOrderItem(orderID int64, lineNo int, item CheckOutItem) (OrderItem, error) {
+	orderItem := OrderItem{
+		OrderID:      orderID,
+		LineNo:       lineNo,
+		ProductID:    item.ProductID,
+		Quantity:     item.Quantity,
+		Currency:     item.Price.Currency,
+		PriceAmount:  item.Price.Amount,
+		ExchangeRate: item.ExchangeRate,
+		Discounts:    "[]",
+	}
+	if item.BasePrice != nil {
+		orderItem.BasePriceAmount = &item.BasePrice.Amount
+		orderItem.BaseCurrency = &item.BasePrice.Currency
+	}
+	if len(item.Discounts) > 0 {
+		for _, discount := range item.Discounts {
+			orderItem.DiscountAmount += discount.Amount.Amount
+		}
+		discountsJSON, err := json.Marshal(item.Discounts)
+		if err != nil {
+			return OrderItem{}, err
+		}
+		orderItem.Discounts = string(discountsJSON)
+	}
+	if item.Tax != nil {
+		orderItem.TaxName = &item.Tax.Name
+		orderItem.TaxRateBps = item.Tax.RateBps
+		orderItem.TaxTaxableAmount = item.Tax.Taxable.Amount
+		orderItem.TaxAmount = item.Tax.Amount.Amount
+	}
+	if item.Product != nil {
+		orderItem.ProductSKU = &item.Product.SKU
+		orderItem.ProductName = &item.Product.Name
+		orderItem.ProductDescription = &item.Product.Description
+		orderItem.ProductCategoryID = &item.Product.CategoryID
+		orderItem.ProductCategory = &item.Product.Category
+		orderItem.ProductPriceAmount = &item.Product.Price.Amount
+		orderItem.ProductPriceCurrency = &item.Product.Price.Currency
+	}
+	return orderItem, nil
+}
+
+// CheckOutItem converts the row back to the item as it was checked out.
+func (i OrderItem) CheckOutItem() (CheckOutItem, error) {
+	item := CheckOutItem{
+		ProductID:    i.ProductID,
+		Quantity:     i.Quantity,
+		Price:        money.New(i.PriceAmount, i.Currency),
+		ExchangeRate: i.ExchangeRate,
+	}
+	if i.BasePriceAmount != nil && i.BaseCurrency != nil {
+		basePrice := money.New(*i.BasePriceAmount, *i.BaseCurrency)
+		item.BasePrice = &basePrice
+	}
+	if i.Discounts != "" && i.Discounts != "[]" {
+		err := json.Unmarshal([]byte(i.Discounts), &item.Discounts)
+		if err != nil {
+			return CheckOutItem{}, err
+		}
+	}
+	if i.TaxName != nil {
+		item.Tax = &TaxBreakdown{
+			Name:    *i.TaxName,
+			RateBps: i.TaxRateBps,
+			Taxable: money.New(i.TaxTaxableAmount, i.Currency),
+			Amount:  money.New(i.TaxAmount, i.Currency),
+		}
+	}
+	if i.ProductName != nil {
+		item.Product = &ProductSnapshot{
+			SKU:         stringValue(i.ProductSKU),
+			Name:        *i.ProductName,
+			Description: stringValue(i.ProductDescription),
+			Category:    stringValue(i.ProductCategory),
+		}
+		if i.ProductCategoryID != nil {
+			item.Product.CategoryID = *i.ProductCategoryID
+		}
+		if i.ProductPriceAmount != nil && i.ProductPriceCurrency != nil {
+			item.Product.Price = money.New(*i.ProductPriceAmount, *i.ProductPriceCurrency)
+		}
+	}
+	return item, nil
+}
+
+// Statushistory converts the row to its entry in the order history response.
+func (h OrderStatusHistory) Statushistory() Statushistory {
+	entry := Statushistory{
+		Status:    h.Status,
+		Timestamp: h.CreateTime.Format(time.RFC3339Nano),
+		Note:      h.Note,
+	}
+	if h.ReturnID != nil {
+		entry.ReturnID = *h.ReturnID
+	}
+	return entry
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
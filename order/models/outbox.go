@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type OrderOutbox struct {
+	ID              int64
+	AggregateID     int64
+	Topic           string
+	EventKey        string
+	Payload         string
+	Status          string
+	Attempts        int
+	LastError       string
+	NextAttemptTime time.Time
+	CreateTime      time.Time
+	SentTime        *time.Time
+}
+
+type OutboxStats struct {
+	Pending          int64
+	Failed           int64
+	OldestPendingAge time.Duration
+}
+
+type OutboxMetrics struct {
+	Pending                 int64   `json:"pending"`
+	Failed                  int64   `json:"failed"`
+	OldestPendingAgeSeconds float64 `json:"oldest_pending_age_seconds"`
+	Published               uint64  `json:"published"`
+	PublishErrors           uint64  `json:"publish_errors"`
+}
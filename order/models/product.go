@@ -1,14 +1,51 @@
 package models
 
-type GetProductInfo struct {
-	Product `json:"product"`
+import "money"
+
+type GetProductsBatchRequest struct {
+	IDs      []int64 `json:"ids"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+type GetProductsBatchResponse struct {
+	Products   []Product `json:"products"`
+	MissingIDs []int64   `json:"missing_ids"`
 }
 
 type Product struct {
-	ID          int64   `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	CategoryID  int     `json:"category_id"`
-	Stock       int     `json:"stock"`
+	ID           int64       `json:"id"`
+	SKU          string      `json:"sku"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Price        money.Money `json:"price"`
+	CategoryID   int         `json:"category_id"`
+	CategoryName string      `json:"category_name"`
+	Stock        int         `json:"stock"`
+
+	// BasePrice and ExchangeRate are set when Price was converted to a requested currency
+	BasePrice    *money.Money `json:"base_price,omitempty"`
+	ExchangeRate string       `json:"exchange_rate,omitempty"`
+}
+
+type StockReservationItem struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+type ReserveStockRequest struct {
+	ReservationID string                 `json:"reservation_id"`
+	Items         []StockReservationItem `json:"items"`
+}
+
+type StockReservationRequest struct {
+	ReservationID string `json:"reservation_id"`
+}
+
+type ProductErrorResponse struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+type RestockRequest struct {
+	RestockID string                 `json:"restock_id"`
+	Items     []StockReservationItem `json:"items"`
 }
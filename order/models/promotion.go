@@ -0,0 +1,76 @@
+package models
+
+import (
+	"money"
+	"time"
+)
+
+// Promotion is a coupon redeemable at checkout. Amounts are in minor units of Currency; percentages
+// are in basis points (1000 = 10%).
+type Promotion struct {
+	ID                int64      `json:"id"`
+	Code              string     `json:"code"`
+	Name              string     `json:"name"`
+	Type              string     `json:"type"`
+	DiscountBps       int64      `json:"discount_bps"`
+	DiscountAmount    int64      `json:"discount_amount"`
+	Currency          string     `json:"currency"`
+	CategoryID        *int       `json:"category_id"`
+	BuyQty            int        `json:"buy_qty"`
+	GetQty            int        `json:"get_qty"`
+	MinSpendAmount    int64      `json:"min_spend_amount"`
+	UsageLimit        int        `json:"usage_limit"`
+	UsageLimitPerUser int        `json:"usage_limit_per_user"`
+	UsageCount        int        `json:"usage_count"`
+	Stackable         bool       `json:"stackable"`
+	Active            bool       `json:"active"`
+	StartTime         time.Time  `json:"start_time"`
+	EndTime           *time.Time `json:"end_time"`
+	CreateTime        time.Time  `json:"create_time"`
+	UpdateTime        time.Time  `json:"update_time"`
+}
+
+// PromotionRequest creates or replaces a promotion. Limits of zero mean unlimited, a missing
+// StartTime starts the promotion right away and a missing Active makes it active.
+type PromotionRequest struct {
+	Code              string     `json:"code" binding:"required"`
+	Name              string     `json:"name" binding:"required"`
+	Type              string     `json:"type" binding:"required"`
+	DiscountBps       int64      `json:"discount_bps"`
+	DiscountAmount    int64      `json:"discount_amount"`
+	Currency          string     `json:"currency" binding:"required"`
+	CategoryID        *int       `json:"category_id"`
+	BuyQty            int        `json:"buy_qty"`
+	GetQty            int        `json:"get_qty"`
+	MinSpendAmount    int64      `json:"min_spend_amount"`
+	UsageLimit        int        `json:"usage_limit"`
+	UsageLimitPerUser int        `json:"usage_limit_per_user"`
+	Stackable         bool       `json:"stackable"`
+	Active            *bool      `json:"active"`
+	StartTime         *time.Time `json:"start_time"`
+	EndTime           *time.Time `json:"end_time"`
+}
+
+// PromotionUsage records a promotion redeemed by an order.
+type PromotionUsage struct {
+	ID             int64
+	PromotionID    int64
+	UserID         int64
+	OrderID        int64
+	DiscountAmount money.Money `gorm:"embedded;embeddedPrefix:discount_"`
+	CreateTime     time.Time
+}
+
+// LineDiscount is the part of a promotion's discount taken off one order line.
+type LineDiscount struct {
+	Code   string      `json:"code"`
+	Amount money.Money `json:"amount"`
+}
+
+// AppliedPromotion is a promotion applied to an order with its total discount.
+type AppliedPromotion struct {
+	PromotionID int64       `json:"promotion_id"`
+	Code        string      `json:"code"`
+	Type        string      `json:"type"`
+	Amount      money.Money `json:"amount"`
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"money"
+	"time"
+)
+
+// OrderReturn is a request to send back some items of a completed order. Items holds the JSON
+// encoded []ReturnItem.
+type OrderReturn struct {
+	ID           int64
+	OrderID      int64
+	UserID       int64
+	Status       string
+	Reason       string
+	Items        string
+	RefundAmount money.Money `gorm:"embedded;embeddedPrefix:refund_"`
+	Restock      bool
+	AdminNote    string
+	CreateTime   time.Time
+	UpdateTime   time.Time
+}
+
+// ReturnItem is a returned quantity of an order line and the share of the line's paid amount it
+// refunds.
+type ReturnItem struct {
+	ProductID    int64       `json:"product_id"`
+	Quantity     int         `json:"quantity"`
+	RefundAmount money.Money `json:"refund_amount"`
+}
+
+type ReturnItemRequest struct {
+	ProductID int64 `json:"product_id" binding:"required"`
+	Quantity  int   `json:"quantity" binding:"required,min=1"`
+}
+
+type CreateReturnRequest struct {
+	Items  []ReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+	Reason string              `json:"reason" binding:"required,max=500"`
+}
+
+type ReviewReturnRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+type ReceiveReturnRequest struct {
+	// Restock puts the received items back on sale
+	Restock bool   `json:"restock"`
+	Note    string `json:"note" binding:"max=500"`
+}
+
+type ReturnResponse struct {
+	ID           int64        `json:"id"`
+	OrderID      int64        `json:"order_id"`
+	UserID       int64        `json:"user_id"`
+	Status       string       `json:"status"`
+	Reason       string       `json:"reason"`
+	Items        []ReturnItem `json:"items"`
+	RefundAmount money.Money  `json:"refund_amount"`
+	Restock      bool         `json:"restock"`
+	AdminNote    string       `json:"admin_note"`
+	CreateTime   time.Time    `json:"create_time"`
+	UpdateTime   time.Time    `json:"update_time"`
+}
+
+// ReturnEvent is published on order.return_updated every time a return changes status.
+type ReturnEvent struct {
+	ReturnID     int64        `json:"return_id"`
+	OrderID      int64        `json:"order_id"`
+	UserID       int64        `json:"user_id"`
+	Status       string       `json:"status"`
+	Items        []ReturnItem `json:"items"`
+	RefundAmount money.Money  `json:"refund_amount"`
+	Restock      bool         `json:"restock"`
+	Note         string       `json:"note,omitempty"`
+	OccurredAt   string       `json:"occurred_at"`
+}
+
+// RefundRequestedEvent is published on refund.requested once the items of a return are received,
+// for the payment service to pay the refund back.
+type RefundRequestedEvent struct {
+	ReturnID    int64       `json:"return_id"`
+	OrderID     int64       `json:"order_id"`
+	UserID      int64       `json:"user_id"`
+	Amount      money.Money `json:"amount"`
+	Reason      string      `json:"reason"`
+	RequestedAt string      `json:"requested_at"`
+}
+
+// OrderRefundedEvent is published once the refund of a return has been paid back.
+type OrderRefundedEvent struct {
+	OrderID    int64       `json:"order_id"`
+	ReturnID   int64       `json:"return_id"`
+	UserID     int64       `json:"user_id"`
+	Amount     money.Money `json:"amount"`
+	RefundID   int64       `json:"refund_id"`
+	RefundedAt string      `json:"refunded_at"`
+}
+
+// RefundEvent is published by the payment service on payment.refunded and payment.refund_failed.
+type RefundEvent struct {
+	RefundID      int64       `json:"refund_id"`
+	ReturnID      int64       `json:"return_id"`
+	OrderID       int64       `json:"order_id"`
+	UserID        int64       `json:"user_id"`
+	Amount        money.Money `json:"amount"`
+	Status        string      `json:"status"`
+	Provider      string      `json:"provider"`
+	ProviderRef   string      `json:"provider_ref"`
+	FailureReason string      `json:"failure_reason,omitempty"`
+	OccurredAt    string      `json:"occurred_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ShipmentEvent is published by the fulfillment service on shipment.updated every time a parcel of
+// an order changes status.
+type ShipmentEvent struct {
+	ShipmentID     int64            `json:"shipment_id"`
+	OrderID        int64            `json:"order_id"`
+	UserID         int64            `json:"user_id"`
+	ParcelNo       int              `json:"parcel_no"`
+	ParcelCount    int              `json:"parcel_count"`
+	Items          []OrderEventItem `json:"items"`
+	Carrier        string           `json:"carrier"`
+	TrackingNumber string           `json:"tracking_number"`
+	Status         string           `json:"status"`
+	OccurredAt     string           `json:"occurred_at"`
+}
+
+// OrderShipment is the latest known status of one parcel of an order.
+type OrderShipment struct {
+	ShipmentID     int64     `json:"shipment_id" gorm:"primaryKey"`
+	OrderID        int64     `json:"-"`
+	ParcelNo       int       `json:"parcel_no"`
+	ParcelCount    int       `json:"parcel_count"`
+	Carrier        string    `json:"carrier"`
+	TrackingNumber string    `json:"tracking_number"`
+	Status         string    `json:"status"`
+	UpdateTime     time.Time `json:"update_time"`
+}
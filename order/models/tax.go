@@ -0,0 +1,28 @@
+package models
+
+import (
+	"money"
+	"time"
+)
+
+// TaxRate is the tax of a region, optionally only for one product category. Rates are in basis
+// points (1100 = 11%).
+type TaxRate struct {
+	ID         int64
+	Region     string
+	CategoryID *int
+	Name       string
+	RateBps    int64
+	Active     bool
+	CreateTime time.Time
+	UpdateTime time.Time
+}
+
+// TaxBreakdown is the tax charged at one rate, on one line or summed up for the order. Taxable is
+// the amount the tax was worked out on, excluding the tax itself.
+type TaxBreakdown struct {
+	Name    string      `json:"name"`
+	RateBps int64       `json:"rate_bps"`
+	Taxable money.Money `json:"taxable"`
+	Amount  money.Money `json:"amount"`
+}
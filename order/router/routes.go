@@ -7,9 +7,39 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(router *gin.Engine, orderHander handler.OrderHandler, jwtSecret string) {
+func SetupRouter(router *gin.Engine, orderHander handler.OrderHandler, outboxHandler handler.OutboxHandler, jwtSecret string) {
 	router.Use(middleware.RequestLogger())
+	// operational endpoints, registered before the auth middleware
+	router.GET("/metrics/outbox", outboxHandler.GetOutboxMetrics)
+
 	router.Use(middleware.AuthMiddleware(jwtSecret))
 	router.POST("/v1/checkout", orderHander.CheckoutOrder)
 	router.GET("/v1/order_history", orderHander.GetOrderHistory)
+	router.GET("/v1/order/:id", orderHander.GetOrderDetail)
+	router.POST("/v1/order/:id/cancel", orderHander.CancelOrder)
+	router.POST("/v1/order/:id/returns", orderHander.CreateReturn)
+	router.GET("/v1/order/:id/returns", orderHander.GetOrderReturns)
+
+	router.GET("/v1/cart", orderHander.GetCart)
+	router.DELETE("/v1/cart", orderHander.ClearCart)
+	router.POST("/v1/cart/items", orderHander.AddCartItem)
+	router.PUT("/v1/cart/items/:product_id", orderHander.UpdateCartItem)
+	router.DELETE("/v1/cart/items/:product_id", orderHander.RemoveCartItem)
+	router.POST("/v1/cart/checkout", orderHander.CheckoutCart)
+
+	admin := router.Group("/v1/admin", middleware.AdminOnly())
+	admin.GET("/orders", orderHander.SearchOrders)
+	admin.GET("/orders/export", orderHander.ExportOrders)
+	admin.GET("/orders/:id", orderHander.GetOrderDetail)
+	admin.POST("/orders/:id/status", orderHander.ForceOrderStatus)
+	admin.GET("/returns", orderHander.GetReturns)
+	admin.POST("/returns/:id/approve", orderHander.ApproveReturn)
+	admin.POST("/returns/:id/reject", orderHander.RejectReturn)
+	admin.POST("/returns/:id/receive", orderHander.ReceiveReturn)
+	admin.POST("/returns/:id/refund", orderHander.RetryRefund)
+	admin.GET("/promotions", orderHander.GetPromotions)
+	admin.POST("/promotions", orderHander.CreatePromotion)
+	admin.GET("/promotions/:id", orderHander.GetPromotion)
+	admin.PUT("/promotions/:id", orderHander.UpdatePromotion)
+	admin.DELETE("/promotions/:id", orderHander.DeactivatePromotion)
 }
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"payment/cmd/payment/usecase"
+	constant "payment/infrastructure/constans"
+	"payment/infrastructure/log"
+	"payment/kafka"
+	"payment/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentHandler struct {
+	PaymentUseCase *usecase.PaymentUseCase
+}
+
+func NewPaymentHandler(paymentUseCase *usecase.PaymentUseCase) *PaymentHandler {
+	return &PaymentHandler{
+		PaymentUseCase: paymentUseCase,
+	}
+}
+
+// HandleOrderCreated consumes the order.created topic.
+func (h *PaymentHandler) HandleOrderCreated(ctx context.Context, value []byte) error {
+	var event models.OrderCreatedEvent
+	if err := kafka.DecodeOrderEvent(value, kafka.EventTypeOrderCreated, 1, &event); err != nil {
+		// a malformed message will never succeed, park it on the dead-letter topic
+		return kafka.Permanent(err)
+	}
+
+	err := h.PaymentUseCase.ProcessOrderCreated(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.PaymentUseCase.ProcessOrderCreated got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HandleOrderCancelled consumes the order.cancelled topic.
+func (h *PaymentHandler) HandleOrderCancelled(ctx context.Context, value []byte) error {
+	var event models.OrderCancelledEvent
+	if err := kafka.DecodeOrderEvent(value, kafka.EventTypeOrderCancelled, 1, &event); err != nil {
+		return kafka.Permanent(err)
+	}
+
+	err := h.PaymentUseCase.ProcessOrderCancelled(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.PaymentUseCase.ProcessOrderCancelled got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HandleRefundRequested consumes the refund.requested topic.
+func (h *PaymentHandler) HandleRefundRequested(ctx context.Context, value []byte) error {
+	var event models.RefundRequestedEvent
+	if err := kafka.DecodeOrderEvent(value, kafka.EventTypeRefundRequested, 1, &event); err != nil {
+		return kafka.Permanent(err)
+	}
+
+	err := h.PaymentUseCase.ProcessRefundRequested(ctx, event)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"event": event,
+		}).Errorf("h.PaymentUseCase.ProcessRefundRequested got error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (h *PaymentHandler) GetPaymentByOrderID(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	payment, err := h.PaymentUseCase.GetPaymentByOrderID(c.Request.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, constant.ErrPaymentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Logger.WithFields(logrus.Fields{
+			"orderID": orderID,
+		}).Errorf("h.PaymentUseCase.GetPaymentByOrderID got error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": payment,
+	})
+}
+
+func (h *PaymentHandler) GetRefundsByOrderID(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	refunds, err := h.PaymentUseCase.GetRefundsByOrderID(c.Request.Context(), orderID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"orderID": orderID,
+		}).Errorf("h.PaymentUseCase.GetRefundsByOrderID got error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": refunds,
+	})
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	constant "payment/infrastructure/constans"
+	"payment/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *PaymentRepository) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	tx := r.Database.Begin().WithContext(ctx)
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// InsertPayment creates the payment of an order and reports false when the order already has one.
+func (r *PaymentRepository) InsertPayment(ctx context.Context, payment *models.Payment) (bool, error) {
+	return r.InsertPaymentTx(ctx, r.Database, payment)
+}
+
+func (r *PaymentRepository) InsertPaymentTx(ctx context.Context, tx *gorm.DB, payment *models.Payment) (bool, error) {
+	result := tx.WithContext(ctx).Table("payment").
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "order_id"}}, DoNothing: true}).
+		Create(payment)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PaymentRepository) FindPaymentByOrderID(ctx context.Context, orderID int64) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.Database.WithContext(ctx).Table("payment").Where("order_id = ?", orderID).First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *PaymentRepository) FindPaymentByOrderIDForUpdateTx(ctx context.Context, tx *gorm.DB, orderID int64) (*models.Payment, error) {
+	var payment models.Payment
+	err := tx.WithContext(ctx).Table("payment").Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// FindPaymentsToReconcile returns, oldest first, the payments left pending since before and the
+// charged payments of cancelled orders still waiting for their refund.
+func (r *PaymentRepository) FindPaymentsToReconcile(ctx context.Context, before time.Time, limit int) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.Database.WithContext(ctx).Table("payment").
+		Where("(status = ? OR (status = ? AND cancel_requested)) AND update_time < ?",
+			constant.PaymentStatusPending, constant.PaymentStatusSucceeded, before).
+		Order("update_time").
+		Limit(limit).
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// UpdatePaymentTx stores the state of a payment locked by the transaction.
+func (r *PaymentRepository) UpdatePaymentTx(ctx context.Context, tx *gorm.DB, payment *models.Payment) error {
+	err := tx.WithContext(ctx).Table("payment").Where("id = ?", payment.ID).Updates(map[string]interface{}{
+		"status":           payment.Status,
+		"provider_ref":     payment.ProviderRef,
+		"failure_reason":   payment.FailureReason,
+		"charge_attempts":  payment.ChargeAttempts,
+		"cancel_requested": payment.CancelRequested,
+		"refund_ref":       payment.RefundRef,
+		"update_time":      time.Now(),
+	}).Error
+	return err
+}
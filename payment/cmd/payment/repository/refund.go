@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	constant "payment/infrastructure/constans"
+	"payment/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InsertRefund creates the refund of an order return and reports false when the return already
+// has one.
+func (r *PaymentRepository) InsertRefund(ctx context.Context, refund *models.Refund) (bool, error) {
+	result := r.Database.WithContext(ctx).Table("refund").
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "return_id"}}, DoNothing: true}).
+		Create(refund)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PaymentRepository) FindRefundByReturnID(ctx context.Context, returnID int64) (*models.Refund, error) {
+	var refund models.Refund
+	err := r.Database.WithContext(ctx).Table("refund").Where("return_id = ?", returnID).First(&refund).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrRefundNotFound
+		}
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *PaymentRepository) FindRefundsByOrderID(ctx context.Context, orderID int64) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.Database.WithContext(ctx).Table("refund").Where("order_id = ?", orderID).Order("id").Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+func (r *PaymentRepository) UpdateRefundResult(ctx context.Context, refund *models.Refund) error {
+	err := r.Database.WithContext(ctx).Table("refund").Where("id = ?", refund.ID).Updates(map[string]interface{}{
+		"payment_id":     refund.PaymentID,
+		"status":         refund.Status,
+		"provider_ref":   refund.ProviderRef,
+		"failure_reason": refund.FailureReason,
+		"update_time":    time.Now(),
+	}).Error
+	return err
+}
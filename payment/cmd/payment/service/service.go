@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"payment/cmd/payment/repository"
+	constant "payment/infrastructure/constans"
+	"payment/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PaymentService struct {
+	PaymentRepository *repository.PaymentRepository
+}
+
+func NewPaymentService(paymentRepository *repository.PaymentRepository) *PaymentService {
+	return &PaymentService{
+		PaymentRepository: paymentRepository,
+	}
+}
+
+func (s *PaymentService) CreatePayment(ctx context.Context, payment *models.Payment) (bool, error) {
+	isCreated, err := s.PaymentRepository.InsertPayment(ctx, payment)
+	if err != nil {
+		return false, err
+	}
+	return isCreated, nil
+}
+
+func (s *PaymentService) GetPaymentByOrderID(ctx context.Context, orderID int64) (*models.Payment, error) {
+	payment, err := s.PaymentRepository.FindPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// StartCharge records that a pending payment is about to be sent to the provider. It reports false,
+// with payment refreshed, when the payment is no longer pending or when its order was cancelled
+// before it was ever sent, in which case the payment is cancelled. The payment is locked so a
+// cancellation is either seen here or sees the attempt.
+func (s *PaymentService) StartCharge(ctx context.Context, payment *models.Payment) (bool, error) {
+	isStarted := false
+
+	err := s.PaymentRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		current, err := s.PaymentRepository.FindPaymentByOrderIDForUpdateTx(ctx, tx, payment.OrderID)
+		if err != nil {
+			return err
+		}
+		*payment = *current
+		if payment.Status != constant.PaymentStatusPending {
+			return nil
+		}
+		if payment.CancelRequested && payment.ChargeAttempts == 0 {
+			payment.Status = constant.PaymentStatusCancelled
+		} else {
+			payment.ChargeAttempts++
+			isStarted = true
+		}
+		return s.PaymentRepository.UpdatePaymentTx(ctx, tx, payment)
+	})
+	if err != nil {
+		return false, err
+	}
+	return isStarted, nil
+}
+
+// UpdatePaymentResult stores the outcome of a charge, refreshing payment.CancelRequested in case
+// the order was cancelled while it was being charged.
+func (s *PaymentService) UpdatePaymentResult(ctx context.Context, payment *models.Payment) error {
+	return s.PaymentRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		current, err := s.PaymentRepository.FindPaymentByOrderIDForUpdateTx(ctx, tx, payment.OrderID)
+		if err != nil {
+			return err
+		}
+		payment.CancelRequested = current.CancelRequested
+		return s.PaymentRepository.UpdatePaymentTx(ctx, tx, payment)
+	})
+}
+
+// CancelPayment records that the order of payment was cancelled and returns the payment as stored.
+// An order not charged yet gets a cancelled payment, so the charge is skipped when its order.created
+// event arrives; a payment not yet sent to the provider is cancelled. Any other payment keeps its
+// status, a charged one has to be refunded.
+func (s *PaymentService) CancelPayment(ctx context.Context, payment *models.Payment) (*models.Payment, error) {
+	payment.Status = constant.PaymentStatusCancelled
+	payment.CancelRequested = true
+
+	err := s.PaymentRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		isCreated, err := s.PaymentRepository.InsertPaymentTx(ctx, tx, payment)
+		if err != nil || isCreated {
+			return err
+		}
+		current, err := s.PaymentRepository.FindPaymentByOrderIDForUpdateTx(ctx, tx, payment.OrderID)
+		if err != nil {
+			return err
+		}
+		*payment = *current
+		if payment.CancelRequested {
+			return nil
+		}
+		payment.CancelRequested = true
+		if payment.Status == constant.PaymentStatusPending && payment.ChargeAttempts == 0 {
+			payment.Status = constant.PaymentStatusCancelled
+		}
+		return s.PaymentRepository.UpdatePaymentTx(ctx, tx, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// UpdatePaymentRefund stores the refund of the payment of a cancelled order.
+func (s *PaymentService) UpdatePaymentRefund(ctx context.Context, payment *models.Payment) error {
+	return s.PaymentRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		_, err := s.PaymentRepository.FindPaymentByOrderIDForUpdateTx(ctx, tx, payment.OrderID)
+		if err != nil {
+			return err
+		}
+		return s.PaymentRepository.UpdatePaymentTx(ctx, tx, payment)
+	})
+}
+
+func (s *PaymentService) GetPaymentsToReconcile(ctx context.Context, before time.Time, limit int) ([]models.Payment, error) {
+	payments, err := s.PaymentRepository.FindPaymentsToReconcile(ctx, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (s *PaymentService) CreateRefund(ctx context.Context, refund *models.Refund) (bool, error) {
+	isCreated, err := s.PaymentRepository.InsertRefund(ctx, refund)
+	if err != nil {
+		return false, err
+	}
+	return isCreated, nil
+}
+
+func (s *PaymentService) GetRefundByReturnID(ctx context.Context, returnID int64) (*models.Refund, error) {
+	refund, err := s.PaymentRepository.FindRefundByReturnID(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (s *PaymentService) GetRefundsByOrderID(ctx context.Context, orderID int64) ([]models.Refund, error) {
+	refunds, err := s.PaymentRepository.FindRefundsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+func (s *PaymentService) UpdateRefundResult(ctx context.Context, refund *models.Refund) error {
+	err := s.PaymentRepository.UpdateRefundResult(ctx, refund)
+	if err != nil {
+		return err
+	}
+	return nil
+}
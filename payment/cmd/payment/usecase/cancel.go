@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	constant "payment/infrastructure/constans"
+	"payment/infrastructure/log"
+	"payment/models"
+	"payment/provider"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProcessOrderCancelled makes sure the customer doesn't pay for a cancelled order: a payment not
+// charged yet is cancelled, and a charged one is refunded in full. A charge still on its way to the
+// provider is refunded as soon as it succeeds.
+func (uc *PaymentUseCase) ProcessOrderCancelled(ctx context.Context, event models.OrderCancelledEvent) error {
+	payment, err := uc.PaymentService.CancelPayment(ctx, &models.Payment{
+		OrderID:  event.OrderID,
+		UserID:   event.UserID,
+		Amount:   event.TotalAmount,
+		Provider: uc.PaymentProvider.Name(),
+	})
+	if err != nil {
+		return err
+	}
+
+	switch payment.Status {
+	case constant.PaymentStatusPending:
+		log.Logger.WithFields(logrus.Fields{
+			"orderID": payment.OrderID,
+		}).Info("order cancelled while its payment is being charged, it is refunded once charged")
+		return nil
+	case constant.PaymentStatusFailed:
+		return nil
+	default:
+		return uc.settlePayment(ctx, payment)
+	}
+}
+
+// refundCancelledPayment pays the whole payment of a cancelled order back and publishes it on
+// payment.cancelled. The provider is always called with the same reference for an order, and a
+// refund that doesn't go through is returned as an error so it is tried again.
+func (uc *PaymentUseCase) refundCancelledPayment(ctx context.Context, payment *models.Payment) error {
+	refundCtx := ctx
+	if uc.ProviderTimeout > 0 {
+		var cancel context.CancelFunc
+		refundCtx, cancel = context.WithTimeout(ctx, uc.ProviderTimeout)
+		defer cancel()
+	}
+	result, err := uc.PaymentProvider.Refund(refundCtx, provider.RefundRequest{
+		Reference: fmt.Sprintf("order-%d-cancelled", payment.OrderID),
+		OrderID:   payment.OrderID,
+		ChargeRef: payment.ProviderRef,
+		Amount:    payment.Amount,
+	})
+	if err != nil {
+		return fmt.Errorf("refund of cancelled order %d: %w", payment.OrderID, err)
+	}
+
+	payment.Status = constant.PaymentStatusRefunded
+	payment.RefundRef = result.ProviderRef
+	err = uc.PaymentService.UpdatePaymentRefund(ctx, payment)
+	if err != nil {
+		return err
+	}
+	return uc.publishPaymentResult(ctx, payment)
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	constant "payment/infrastructure/constans"
+	"payment/infrastructure/log"
+	"payment/kafka"
+	"payment/models"
+	"payment/provider"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProcessRefundRequested pays back a received return and publishes the outcome. Redelivered events
+// don't refund twice: a refund that already succeeded only has its event published again, and the
+// gateway is always called with the same reference for a return. Refund requests are keyed by the
+// order, so refunds of the same order are never processed concurrently.
+func (uc *PaymentUseCase) ProcessRefundRequested(ctx context.Context, event models.RefundRequestedEvent) error {
+	refund := &models.Refund{
+		ReturnID: event.ReturnID,
+		OrderID:  event.OrderID,
+		UserID:   event.UserID,
+		Amount:   event.Amount,
+		Reason:   event.Reason,
+		Provider: uc.PaymentProvider.Name(),
+		Status:   constant.RefundStatusPending,
+	}
+	isCreated, err := uc.PaymentService.CreateRefund(ctx, refund)
+	if err != nil {
+		return err
+	}
+	if !isCreated {
+		refund, err = uc.PaymentService.GetRefundByReturnID(ctx, event.ReturnID)
+		if err != nil {
+			return err
+		}
+		if refund.Status == constant.RefundStatusSucceeded {
+			return uc.publishRefundResult(ctx, refund)
+		}
+		// a failed refund requested again is retried
+		refund.FailureReason = ""
+	}
+
+	payment, err := uc.refundablePayment(ctx, refund)
+	if err != nil {
+		if !errors.Is(err, constant.ErrRefundNotRefundable) && !errors.Is(err, constant.ErrRefundExceedsPayment) {
+			return err
+		}
+		refund.Status = constant.RefundStatusFailed
+		refund.FailureReason = err.Error()
+		err = uc.PaymentService.UpdateRefundResult(ctx, refund)
+		if err != nil {
+			return err
+		}
+		return uc.publishRefundResult(ctx, refund)
+	}
+	refund.PaymentID = &payment.ID
+
+	if refund.Amount.IsZero() {
+		// nothing was paid for the returned items, e.g. they were free with a promotion
+		refund.Status = constant.RefundStatusSucceeded
+	} else {
+		refundCtx := ctx
+		if uc.ProviderTimeout > 0 {
+			var cancel context.CancelFunc
+			refundCtx, cancel = context.WithTimeout(ctx, uc.ProviderTimeout)
+			defer cancel()
+		}
+		result, err := uc.PaymentProvider.Refund(refundCtx, provider.RefundRequest{
+			Reference: fmt.Sprintf("return-%d", refund.ReturnID),
+			OrderID:   refund.OrderID,
+			ChargeRef: payment.ProviderRef,
+			Amount:    refund.Amount,
+		})
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"orderID":  refund.OrderID,
+				"returnID": refund.ReturnID,
+			}).Errorf("uc.PaymentProvider.Refund got error: %v", err)
+			refund.Status = constant.RefundStatusFailed
+			refund.FailureReason = err.Error()
+		} else {
+			refund.Status = constant.RefundStatusSucceeded
+			refund.ProviderRef = result.ProviderRef
+		}
+	}
+
+	err = uc.PaymentService.UpdateRefundResult(ctx, refund)
+	if err != nil {
+		return err
+	}
+	return uc.publishRefundResult(ctx, refund)
+}
+
+func (uc *PaymentUseCase) GetRefundsByOrderID(ctx context.Context, orderID int64) ([]models.Refund, error) {
+	refunds, err := uc.PaymentService.GetRefundsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// refundablePayment returns the payment refund is paid back from once it is checked that the order
+// was paid and that refund, together with the refunds already paid out, stays within what was paid.
+func (uc *PaymentUseCase) refundablePayment(ctx context.Context, refund *models.Refund) (*models.Payment, error) {
+	payment, err := uc.PaymentService.GetPaymentByOrderID(ctx, refund.OrderID)
+	if err != nil {
+		if errors.Is(err, constant.ErrPaymentNotFound) {
+			return nil, fmt.Errorf("%w: order %d has no payment", constant.ErrRefundNotRefundable, refund.OrderID)
+		}
+		return nil, err
+	}
+	if payment.Status != constant.PaymentStatusSucceeded {
+		return nil, fmt.Errorf("%w: payment of order %d is %s", constant.ErrRefundNotRefundable, refund.OrderID, payment.Status)
+	}
+
+	refunds, err := uc.PaymentService.GetRefundsByOrderID(ctx, refund.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := payment.Amount
+	for _, other := range refunds {
+		if other.ID == refund.ID || other.Status != constant.RefundStatusSucceeded {
+			continue
+		}
+		remaining, err = remaining.Sub(other.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", constant.ErrRefundNotRefundable, err)
+		}
+	}
+	left, err := remaining.Sub(refund.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", constant.ErrRefundNotRefundable, err)
+	}
+	if left.Amount < 0 {
+		return nil, fmt.Errorf("%w: %s requested, %s left", constant.ErrRefundExceedsPayment, refund.Amount, remaining)
+	}
+	return payment, nil
+}
+
+func (uc *PaymentUseCase) publishRefundResult(ctx context.Context, refund *models.Refund) error {
+	topic := kafka.TopicPaymentRefunded
+	if refund.Status == constant.RefundStatusFailed {
+		topic = kafka.TopicPaymentRefundFailed
+	}
+
+	event := models.RefundEvent{
+		RefundID:      refund.ID,
+		ReturnID:      refund.ReturnID,
+		OrderID:       refund.OrderID,
+		UserID:        refund.UserID,
+		Amount:        refund.Amount,
+		Status:        refund.Status,
+		Provider:      refund.Provider,
+		ProviderRef:   refund.ProviderRef,
+		FailureReason: refund.FailureReason,
+		OccurredAt:    time.Now().Format(time.RFC3339Nano),
+	}
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return uc.KafkaProducer.Publish(ctx, topic, fmt.Sprintf("order-%d", refund.OrderID), value)
+}
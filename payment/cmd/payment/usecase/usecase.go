@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"payment/cmd/payment/service"
+	constant "payment/infrastructure/constans"
+	"payment/infrastructure/log"
+	"payment/kafka"
+	"payment/models"
+	"payment/provider"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentUseCase struct {
+	PaymentService  *service.PaymentService
+	PaymentProvider provider.PaymentProvider
+	KafkaProducer   *kafka.KafkaProducer
+	ProviderTimeout time.Duration
+}
+
+func NewPaymentUseCase(paymentService *service.PaymentService, paymentProvider provider.PaymentProvider, kafkaProducer *kafka.KafkaProducer, providerTimeout time.Duration) *PaymentUseCase {
+	return &PaymentUseCase{
+		PaymentService:  paymentService,
+		PaymentProvider: paymentProvider,
+		KafkaProducer:   kafkaProducer,
+		ProviderTimeout: providerTimeout,
+	}
+}
+
+// ProcessOrderCreated charges a newly created order and publishes the outcome. Redelivered events
+// don't charge twice: a payment that already has a result only has its event published again, and
+// the provider is always called with the same reference for an order. An order cancelled before
+// its order.created event is handled is not charged.
+func (uc *PaymentUseCase) ProcessOrderCreated(ctx context.Context, event models.OrderCreatedEvent) error {
+	payment := &models.Payment{
+		OrderID:       event.OrderID,
+		UserID:        event.UserID,
+		Amount:        event.TotalAmount,
+		PaymentMethod: event.PaymentMethod,
+		Provider:      uc.PaymentProvider.Name(),
+		Status:        constant.PaymentStatusPending,
+	}
+	isCreated, err := uc.PaymentService.CreatePayment(ctx, payment)
+	if err != nil {
+		return err
+	}
+	if !isCreated {
+		payment, err = uc.PaymentService.GetPaymentByOrderID(ctx, event.OrderID)
+		if err != nil {
+			return err
+		}
+		if payment.Status != constant.PaymentStatusPending {
+			return uc.settlePayment(ctx, payment)
+		}
+	}
+	return uc.chargePayment(ctx, payment)
+}
+
+// ReconcilePayments settles the payments left unsettled since before: pending ones, whose charge
+// timed out or whose order.created event ended on the dead-letter topic, are charged again and the
+// charged payments of cancelled orders are refunded. It returns the number of payments settled.
+func (uc *PaymentUseCase) ReconcilePayments(ctx context.Context, before time.Time, limit int) (int, error) {
+	payments, err := uc.PaymentService.GetPaymentsToReconcile(ctx, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	var reconciled int
+	for i := range payments {
+		if payments[i].Status == constant.PaymentStatusPending {
+			err = uc.chargePayment(ctx, &payments[i])
+		} else {
+			err = uc.settlePayment(ctx, &payments[i])
+		}
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"orderID": payments[i].OrderID,
+			}).Errorf("uc.ReconcilePayments got error: %v", err)
+			continue
+		}
+		reconciled++
+	}
+	return reconciled, nil
+}
+
+// chargePayment sends a pending payment to the provider and records and publishes the outcome. Only
+// a decline fails the payment: when the provider times out or can't be reached the charge may have
+// gone through, so the payment stays pending and the error is returned to charge it again later.
+func (uc *PaymentUseCase) chargePayment(ctx context.Context, payment *models.Payment) error {
+	isStarted, err := uc.PaymentService.StartCharge(ctx, payment)
+	if err != nil {
+		return err
+	}
+	if !isStarted {
+		return uc.settlePayment(ctx, payment)
+	}
+
+	chargeCtx := ctx
+	if uc.ProviderTimeout > 0 {
+		var cancel context.CancelFunc
+		chargeCtx, cancel = context.WithTimeout(ctx, uc.ProviderTimeout)
+		defer cancel()
+	}
+	result, err := uc.PaymentProvider.Charge(chargeCtx, provider.ChargeRequest{
+		Reference:     chargeReference(payment.OrderID),
+		OrderID:       payment.OrderID,
+		UserID:        payment.UserID,
+		Amount:        payment.Amount,
+		PaymentMethod: payment.PaymentMethod,
+	})
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"orderID": payment.OrderID,
+			"attempt": payment.ChargeAttempts,
+		}).Errorf("uc.PaymentProvider.Charge got error: %v", err)
+		if !errors.Is(err, provider.ErrPaymentDeclined) {
+			return fmt.Errorf("charge of order %d has an unknown outcome: %w", payment.OrderID, err)
+		}
+		payment.Status = constant.PaymentStatusFailed
+		payment.FailureReason = err.Error()
+	} else {
+		payment.Status = constant.PaymentStatusSucceeded
+		payment.ProviderRef = result.ProviderRef
+	}
+
+	err = uc.PaymentService.UpdatePaymentResult(ctx, payment)
+	if err != nil {
+		return err
+	}
+	return uc.settlePayment(ctx, payment)
+}
+
+// settlePayment publishes the result of a payment that is no longer pending, once the payment of a
+// cancelled order has been refunded.
+func (uc *PaymentUseCase) settlePayment(ctx context.Context, payment *models.Payment) error {
+	if payment.Status == constant.PaymentStatusSucceeded && payment.CancelRequested {
+		return uc.refundCancelledPayment(ctx, payment)
+	}
+	return uc.publishPaymentResult(ctx, payment)
+}
+
+func (uc *PaymentUseCase) GetPaymentByOrderID(ctx context.Context, orderID int64) (*models.Payment, error) {
+	payment, err := uc.PaymentService.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (uc *PaymentUseCase) publishPaymentResult(ctx context.Context, payment *models.Payment) error {
+	topic := kafka.TopicPaymentSucceeded
+	switch payment.Status {
+	case constant.PaymentStatusFailed:
+		topic = kafka.TopicPaymentFailed
+	case constant.PaymentStatusCancelled, constant.PaymentStatusRefunded:
+		topic = kafka.TopicPaymentCancelled
+	}
+
+	event := models.PaymentEvent{
+		PaymentID:     payment.ID,
+		OrderID:       payment.OrderID,
+		UserID:        payment.UserID,
+		Amount:        payment.Amount,
+		Status:        payment.Status,
+		Provider:      payment.Provider,
+		ProviderRef:   payment.ProviderRef,
+		FailureReason: payment.FailureReason,
+		OccurredAt:    time.Now().Format(time.RFC3339Nano),
+	}
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return uc.KafkaProducer.Publish(ctx, topic, fmt.Sprintf("order-%d", payment.OrderID), value)
+}
+
+// chargeReference identifies the charge of an order at the provider.
+func chargeReference(orderID int64) string {
+	return fmt.Sprintf("order-%d", orderID)
+}
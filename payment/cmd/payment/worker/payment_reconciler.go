@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"payment/cmd/payment/usecase"
+	"payment/config"
+	"payment/infrastructure/log"
+	"time"
+)
+
+const (
+	defaultReconcileInterval   = time.Minute
+	defaultReconcileStaleAfter = 5 * time.Minute
+	defaultReconcileBatch      = 100
+)
+
+// PaymentReconciler periodically charges again the payments left pending and refunds the charged
+// payments of cancelled orders, so a charge or a refund that failed is settled even once its event
+// is no longer retried.
+type PaymentReconciler struct {
+	PaymentUseCase *usecase.PaymentUseCase
+	Interval       time.Duration
+	StaleAfter     time.Duration
+	BatchSize      int
+}
+
+func NewPaymentReconciler(paymentUseCase *usecase.PaymentUseCase, cfg config.ReconcileConfig) *PaymentReconciler {
+	reconciler := &PaymentReconciler{
+		PaymentUseCase: paymentUseCase,
+		Interval:       cfg.Interval,
+		StaleAfter:     cfg.StaleAfter,
+		BatchSize:      cfg.Batch,
+	}
+	if reconciler.Interval <= 0 {
+		reconciler.Interval = defaultReconcileInterval
+	}
+	if reconciler.StaleAfter <= 0 {
+		reconciler.StaleAfter = defaultReconcileStaleAfter
+	}
+	if reconciler.BatchSize <= 0 {
+		reconciler.BatchSize = defaultReconcileBatch
+	}
+	return reconciler
+}
+
+// Start runs until ctx is cancelled.
+func (r *PaymentReconciler) Start(ctx context.Context) {
+	log.Logger.Info("Payment reconciler started")
+	for {
+		_, err := r.PaymentUseCase.ReconcilePayments(ctx, time.Now().Add(-r.StaleAfter), r.BatchSize)
+		if err != nil {
+			log.Logger.Errorf("r.PaymentUseCase.ReconcilePayments got error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Logger.Info("Payment reconciler stopped")
+			return
+		case <-time.After(r.Interval):
+		}
+	}
+}
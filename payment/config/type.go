@@ -0,0 +1,55 @@
+package config
+
+import "time"
+
+type Config struct {
+	App       AppConfig       `yaml:"app" validate:"required"`
+	Database  DatabaseConfig  `yaml:"database" validate:"required"`
+	Kafka     KafkaConfig     `yaml:"kafka" validate:"required"`
+	Provider  ProviderConfig  `yaml:"provider" validate:"required"`
+	Reconcile ReconcileConfig `yaml:"reconcile"`
+}
+
+type AppConfig struct {
+	Port string `yaml:"port" validate:"required"`
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host" validate:"required"`
+	Port     string `yaml:"port" validate:"required"`
+	User     string `yaml:"user" validate:"required"`
+	Password string `yaml:"password" validate:"required"`
+	Name     string `yaml:"name" validate:"required"`
+}
+
+type KafkaConfig struct {
+	Brokers       []string            `yaml:"brokers" validate:"required"`
+	ConsumerGroup string              `yaml:"consumer_group" mapstructure:"consumer_group" validate:"required"`
+	Consumer      KafkaConsumerConfig `yaml:"consumer"`
+}
+
+type KafkaConsumerConfig struct {
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`
+}
+
+type ProviderConfig struct {
+	Name    string        `yaml:"name" validate:"required"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Mock configures the in-process provider used when Name is "mock"
+	Mock MockProviderConfig `yaml:"mock"`
+}
+
+type MockProviderConfig struct {
+	Mode    string        `yaml:"mode"`
+	Latency time.Duration `yaml:"latency"`
+}
+
+type ReconcileConfig struct {
+	// Interval is how often payments left pending or waiting for a refund are looked for
+	Interval time.Duration `yaml:"interval"`
+	// StaleAfter is how long a payment is left unsettled before it is settled again, longer than the
+	// consumer takes to retry an event
+	StaleAfter time.Duration `yaml:"stale_after" mapstructure:"stale_after"`
+	Batch      int           `yaml:"batch"`
+}
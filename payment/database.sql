@@ -0,0 +1,39 @@
+create table payment (
+    id bigserial primary key,
+    order_id bigint unique not null,
+    user_id bigint not null,
+    amount bigint not null,
+    currency char(3) not null,
+    payment_method varchar(50),
+    provider varchar(50) not null,
+    provider_ref varchar(100),
+    status varchar(20) not null,
+    failure_reason text,
+    charge_attempts int not null default 0,
+    cancel_requested boolean not null default false,
+    refund_ref varchar(100),
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create index idx_payment_status on payment (status, update_time);
+create index idx_payment_cancel_requested on payment (status) where cancel_requested;
+
+create table refund (
+    id bigserial primary key,
+    return_id bigint unique not null,
+    order_id bigint not null,
+    user_id bigint not null,
+    payment_id bigint references payment(id),
+    amount bigint not null,
+    currency char(3) not null,
+    reason text,
+    provider varchar(50) not null,
+    provider_ref varchar(100),
+    status varchar(20) not null,
+    failure_reason text,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create index idx_refund_order on refund (order_id, status);
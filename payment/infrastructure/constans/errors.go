@@ -0,0 +1,10 @@
+package constant
+
+import "errors"
+
+var (
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrRefundNotFound       = errors.New("refund not found")
+	ErrRefundNotRefundable  = errors.New("payment can not be refunded")
+	ErrRefundExceedsPayment = errors.New("refund exceeds the amount paid")
+)
@@ -0,0 +1,17 @@
+package constant
+
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+	// PaymentStatusCancelled is a payment whose order was cancelled before it was charged
+	PaymentStatusCancelled = "cancelled"
+	// PaymentStatusRefunded is a payment refunded in full because its order was cancelled
+	PaymentStatusRefunded = "refunded"
+)
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
@@ -0,0 +1,45 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	TopicOrderCreated        = "order.created"
+	TopicOrderCancelled      = "order.cancelled"
+	TopicPaymentSucceeded    = "payment.succeeded"
+	TopicPaymentFailed       = "payment.failed"
+	TopicPaymentCancelled    = "payment.cancelled"
+	TopicRefundRequested     = "refund.requested"
+	TopicPaymentRefunded     = "payment.refunded"
+	TopicPaymentRefundFailed = "payment.refund_failed"
+)
+
+type KafkaProducer struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaProducer(brokers []string) *KafkaProducer {
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: 10 * time.Millisecond,
+	}
+	return &KafkaProducer{writer: writer}
+}
+
+func (p *KafkaProducer) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	msg := kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: value,
+	}
+	return p.writer.WriteMessages(ctx, msg)
+}
+
+func (p *KafkaProducer) Close() error {
+	return p.writer.Close()
+}
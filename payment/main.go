@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"payment/cmd/payment/handler"
+	"payment/cmd/payment/repository"
+	"payment/cmd/payment/resource"
+	"payment/cmd/payment/service"
+	"payment/cmd/payment/usecase"
+	"payment/cmd/payment/worker"
+	"payment/config"
+	"payment/infrastructure/log"
+	"payment/kafka"
+	"payment/provider"
+	routes "payment/router"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	cfg := config.LoadConfig()
+	db := resource.InitDB(&cfg)
+
+	log.SetupLoger()
+
+	paymentProvider, err := provider.NewPaymentProvider(cfg.Provider)
+	if err != nil {
+		log.Logger.Fatalf("provider.NewPaymentProvider got error: %v", err)
+	}
+	kafkaProducer := kafka.NewKafkaProducer(cfg.Kafka.Brokers)
+	defer kafkaProducer.Close()
+
+	paymentRepository := repository.NewPaymentRepository(db)
+	paymentService := service.NewPaymentService(paymentRepository)
+	paymentUseCase := usecase.NewPaymentUseCase(paymentService, paymentProvider, kafkaProducer, cfg.Provider.Timeout)
+	paymentHandler := handler.NewPaymentHandler(paymentUseCase)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kafkaConsumer := kafka.NewKafkaConsumer(kafka.ConsumerConfig{
+		Brokers:        cfg.Kafka.Brokers,
+		GroupID:        cfg.Kafka.ConsumerGroup,
+		InitialBackoff: cfg.Kafka.Consumer.InitialBackoff,
+		MaxBackoff:     cfg.Kafka.Consumer.MaxBackoff,
+	}, kafkaProducer)
+	kafkaConsumer.Register(kafka.TopicOrderCreated, paymentHandler.HandleOrderCreated)
+	kafkaConsumer.Register(kafka.TopicOrderCancelled, paymentHandler.HandleOrderCancelled)
+	kafkaConsumer.Register(kafka.TopicRefundRequested, paymentHandler.HandleRefundRequested)
+	kafkaConsumer.Start(ctx)
+	defer kafkaConsumer.Close()
+
+	paymentReconciler := worker.NewPaymentReconciler(paymentUseCase, cfg.Reconcile)
+	go paymentReconciler.Start(ctx)
+
+	port := cfg.App.Port
+	router := gin.Default()
+
+	routes.SetupRouter(router, *paymentHandler)
+
+	router.Run(":" + port)
+
+	log.Logger.Printf("Server Running on port: %s", port)
+}
@@ -0,0 +1,5 @@
+-- amounts move from numeric major units to integer minor units plus a currency code.
+-- every existing payment is in USD, which has two decimal places.
+alter table payment alter column amount type bigint using round(amount * 100);
+alter table payment add column currency char(3) not null default 'USD';
+alter table payment alter column currency drop default;
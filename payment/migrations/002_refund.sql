@@ -0,0 +1,19 @@
+-- refunds of returned items, one per order return so a redelivered request is only paid out once
+create table refund (
+    id bigserial primary key,
+    return_id bigint unique not null,
+    order_id bigint not null,
+    user_id bigint not null,
+    payment_id bigint references payment(id),
+    amount bigint not null,
+    currency char(3) not null,
+    reason text,
+    provider varchar(50) not null,
+    provider_ref varchar(100),
+    status varchar(20) not null,
+    failure_reason text,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create index idx_refund_order on refund (order_id, status);
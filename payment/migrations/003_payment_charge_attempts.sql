@@ -0,0 +1,5 @@
+-- a charge that timed out stays pending and is sent again with the same reference, the attempts
+-- tell a payment never sent to the provider from one whose outcome is unknown.
+alter table payment add column charge_attempts int not null default 0;
+
+create index idx_payment_status on payment (status, update_time);
@@ -0,0 +1,5 @@
+-- the payment of a cancelled order is not charged, or refunded in full when it already was.
+alter table payment add column cancel_requested boolean not null default false;
+alter table payment add column refund_ref varchar(100);
+
+create index idx_payment_cancel_requested on payment (status) where cancel_requested;
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"eventschema"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// registryDir holds the contracts the order service checks its events against before publishing.
+const registryDir = "../../order/kafka/schema/registry/consumers/payment"
+
+// TestEventsMatchTheirContract keeps the contracts of the payment service in the order registry in
+// step with the structs it decodes the events into.
+func TestEventsMatchTheirContract(t *testing.T) {
+	events := map[string]interface{}{
+		"OrderCreated/v1.json":    OrderCreatedEvent{},
+		"OrderCancelled/v1.json":  OrderCancelledEvent{},
+		"RefundRequested/v1.json": RefundRequestedEvent{},
+	}
+	contracts, err := filepath.Glob(filepath.Join(registryDir, "*", "v*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contracts) != len(events) {
+		t.Errorf("registry has contracts %v, want one for each of the %d events read", contracts, len(events))
+	}
+	for name, event := range events {
+		data, err := os.ReadFile(filepath.Join(registryDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contract, err := eventschema.Parse(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, problem := range eventschema.CheckStruct(contract, event) {
+			t.Errorf("%s: %s", name, problem)
+		}
+	}
+}
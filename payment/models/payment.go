@@ -0,0 +1,57 @@
+package models
+
+import (
+	"money"
+	"time"
+)
+
+// Payment is the charge of an order. ChargeAttempts counts the charges sent to the provider: a
+// pending payment with attempts may have been charged already, its outcome is unknown.
+// CancelRequested is set once the order is cancelled: a payment never sent to the provider is then
+// cancelled, and one that was charged is refunded in full under RefundRef.
+type Payment struct {
+	ID              int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID         int64       `json:"order_id"`
+	UserID          int64       `json:"user_id"`
+	Amount          money.Money `json:"amount" gorm:"embedded"`
+	PaymentMethod   string      `json:"payment_method"`
+	Provider        string      `json:"provider"`
+	ProviderRef     string      `json:"provider_ref"`
+	Status          string      `json:"status"`
+	FailureReason   string      `json:"failure_reason"`
+	ChargeAttempts  int         `json:"charge_attempts"`
+	CancelRequested bool        `json:"cancel_requested"`
+	RefundRef       string      `json:"refund_ref"`
+	CreateTime      time.Time   `json:"create_time" gorm:"autoCreateTime"`
+	UpdateTime      time.Time   `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+// OrderCreatedEvent is published by the order service on the order.created topic.
+type OrderCreatedEvent struct {
+	OrderID         int64       `json:"order_id"`
+	UserID          int64       `json:"user_id"`
+	TotalAmount     money.Money `json:"total_amount"`
+	PaymentMethod   string      `json:"payment_method"`
+	ShippingAddress string      `json:"shipping_address"`
+}
+
+// OrderCancelledEvent is published by the order service on the order.cancelled topic.
+type OrderCancelledEvent struct {
+	OrderID     int64       `json:"order_id"`
+	UserID      int64       `json:"user_id"`
+	TotalAmount money.Money `json:"total_amount"`
+}
+
+// PaymentEvent is published on payment.succeeded, payment.failed and, for the payment of a
+// cancelled order, on payment.cancelled with the status cancelled or refunded.
+type PaymentEvent struct {
+	PaymentID     int64       `json:"payment_id"`
+	OrderID       int64       `json:"order_id"`
+	UserID        int64       `json:"user_id"`
+	Amount        money.Money `json:"amount"`
+	Status        string      `json:"status"`
+	Provider      string      `json:"provider"`
+	ProviderRef   string      `json:"provider_ref"`
+	FailureReason string      `json:"failure_reason,omitempty"`
+	OccurredAt    string      `json:"occurred_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"money"
+	"time"
+)
+
+type Refund struct {
+	ID            int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ReturnID      int64       `json:"return_id"`
+	OrderID       int64       `json:"order_id"`
+	UserID        int64       `json:"user_id"`
+	PaymentID     *int64      `json:"payment_id"`
+	Amount        money.Money `json:"amount" gorm:"embedded"`
+	Reason        string      `json:"reason"`
+	Provider      string      `json:"provider"`
+	ProviderRef   string      `json:"provider_ref"`
+	Status        string      `json:"status"`
+	FailureReason string      `json:"failure_reason"`
+	CreateTime    time.Time   `json:"create_time" gorm:"autoCreateTime"`
+	UpdateTime    time.Time   `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+// RefundRequestedEvent is published by the order service on refund.requested once the items of a
+// return have been received.
+type RefundRequestedEvent struct {
+	ReturnID    int64       `json:"return_id"`
+	OrderID     int64       `json:"order_id"`
+	UserID      int64       `json:"user_id"`
+	Amount      money.Money `json:"amount"`
+	Reason      string      `json:"reason"`
+	RequestedAt string      `json:"requested_at"`
+}
+
+// RefundEvent is published on payment.refunded and payment.refund_failed.
+type RefundEvent struct {
+	RefundID      int64       `json:"refund_id"`
+	ReturnID      int64       `json:"return_id"`
+	OrderID       int64       `json:"order_id"`
+	UserID        int64       `json:"user_id"`
+	Amount        money.Money `json:"amount"`
+	Status        string      `json:"status"`
+	Provider      string      `json:"provider"`
+	ProviderRef   string      `json:"provider_ref"`
+	FailureReason string      `json:"failure_reason,omitempty"`
+	OccurredAt    string      `json:"occurred_at"`
+}
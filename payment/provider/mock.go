@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MockModeSucceed = "succeed"
+	MockModeFail    = "fail"
+	MockModeTimeout = "timeout"
+)
+
+// MockProvider is an in-process gateway for development and tests. Depending on its mode every
+// charge and refund succeeds, is declined, or hangs until the caller's context expires. Like a real
+// gateway it answers a retried charge with the charge already made under the same reference.
+type MockProvider struct {
+	mu      sync.RWMutex
+	mode    string
+	latency time.Duration
+	charges map[string]ChargeResult
+}
+
+func NewMockProvider(mode string, latency time.Duration) *MockProvider {
+	if mode == "" {
+		mode = MockModeSucceed
+	}
+	return &MockProvider{
+		mode:    mode,
+		latency: latency,
+		charges: map[string]ChargeResult{},
+	}
+}
+
+func (p *MockProvider) Name() string {
+	return "mock"
+}
+
+func (p *MockProvider) SetMode(mode string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mode = mode
+}
+
+func (p *MockProvider) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	mode, err := p.wait(ctx)
+	if err != nil {
+		return ChargeResult{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if result, ok := p.charges[req.Reference]; ok {
+		return result, nil
+	}
+
+	switch mode {
+	case MockModeSucceed:
+		result := ChargeResult{ProviderRef: "mock-" + uuid.New().String()}
+		if req.Reference != "" {
+			p.charges[req.Reference] = result
+		}
+		return result, nil
+	case MockModeFail:
+		return ChargeResult{}, fmt.Errorf("%w: mock provider configured to fail", ErrPaymentDeclined)
+	default:
+		return ChargeResult{}, fmt.Errorf("unknown mock provider mode: %s", mode)
+	}
+}
+
+func (p *MockProvider) Refund(ctx context.Context, req RefundRequest) (RefundResult, error) {
+	mode, err := p.wait(ctx)
+	if err != nil {
+		return RefundResult{}, err
+	}
+
+	switch mode {
+	case MockModeSucceed:
+		return RefundResult{ProviderRef: "mock-refund-" + uuid.New().String()}, nil
+	case MockModeFail:
+		return RefundResult{}, fmt.Errorf("%w: mock provider configured to fail", ErrRefundDeclined)
+	default:
+		return RefundResult{}, fmt.Errorf("unknown mock provider mode: %s", mode)
+	}
+}
+
+// wait simulates the gateway latency of the current mode and returns the mode to answer with.
+func (p *MockProvider) wait(ctx context.Context) (string, error) {
+	p.mu.RLock()
+	mode, latency := p.mode, p.latency
+	p.mu.RUnlock()
+
+	if mode == MockModeTimeout {
+		<-ctx.Done()
+		return "", ErrProviderTimeout
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", ErrProviderTimeout
+	case <-time.After(latency):
+	}
+	return mode, nil
+}
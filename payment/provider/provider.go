@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"money"
+	"payment/config"
+)
+
+var (
+	ErrPaymentDeclined = errors.New("payment declined")
+	ErrRefundDeclined  = errors.New("refund declined")
+	ErrProviderTimeout = errors.New("payment provider timed out")
+)
+
+// ChargeRequest charges Amount for an order. Reference identifies the charge at the gateway, so a
+// request retried after a timeout is not charged twice and answers with the first charge.
+type ChargeRequest struct {
+	Reference     string
+	OrderID       int64
+	UserID        int64
+	Amount        money.Money
+	PaymentMethod string
+}
+
+type ChargeResult struct {
+	ProviderRef string
+}
+
+// RefundRequest pays Amount of the charge ChargeRef back. Reference identifies the refund at the
+// gateway, so a retried request is not paid out twice.
+type RefundRequest struct {
+	Reference string
+	OrderID   int64
+	ChargeRef string
+	Amount    money.Money
+}
+
+type RefundResult struct {
+	ProviderRef string
+}
+
+// PaymentProvider charges and refunds a customer through an external gateway. Charge returns
+// ErrPaymentDeclined and Refund ErrRefundDeclined when the gateway refuses. Any other error, like
+// ErrProviderTimeout when it does not answer in time, leaves the outcome unknown: the request may
+// have gone through, and must be retried with the same reference to find out.
+type PaymentProvider interface {
+	Name() string
+	Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error)
+	Refund(ctx context.Context, req RefundRequest) (RefundResult, error)
+}
+
+func NewPaymentProvider(cfg config.ProviderConfig) (PaymentProvider, error) {
+	switch cfg.Name {
+	case "mock":
+		return NewMockProvider(cfg.Mock.Mode, cfg.Mock.Latency), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider: %s", cfg.Name)
+	}
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"payment/cmd/payment/handler"
+	"payment/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRouter(router *gin.Engine, paymentHandler handler.PaymentHandler) {
+	router.Use(middleware.RequestLogger())
+	router.GET("/v1/payment/order/:order_id", paymentHandler.GetPaymentByOrderID)
+	router.GET("/v1/payment/order/:order_id/refunds", paymentHandler.GetRefundsByOrderID)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"money"
+	"net/http"
+	constant "product/infrastructure/constans"
+	"product/infrastructure/log"
+	"product/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *ProductHandler) GetExchangeRates(c *gin.Context) {
+	exchangeRates, err := h.ProductUseCase.GetExchangeRates(c.Request.Context())
+	if err != nil {
+		log.Logger.Errorf("h.ProductUseCase.GetExchangeRates got error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": "Internal Server Error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Successfully get exchange rates",
+		"base_currency":  h.ProductUseCase.BaseCurrency,
+		"exchange_rates": exchangeRates,
+	})
+}
+
+func (h *ProductHandler) SetExchangeRate(c *gin.Context) {
+	var param models.ExchangeRate
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid Input",
+		})
+		return
+	}
+
+	err := h.ProductUseCase.SetExchangeRate(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUseCase.SetExchangeRate got error: %v", err)
+		if isCurrencyError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": "Internal Server Error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Successfully set exchange rate",
+		"exchange_rate": param,
+	})
+}
+
+// isCurrencyError reports whether err comes from an unsupported currency or a missing or
+// invalid exchange rate, which are the caller's mistake.
+func isCurrencyError(err error) bool {
+	return errors.Is(err, constant.ErrInvalidCurrency) ||
+		errors.Is(err, constant.ErrExchangeRateNotFound) ||
+		errors.Is(err, money.ErrInvalidRate) ||
+		errors.Is(err, money.ErrUnknownCurrency)
+}
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"product/cmd/product/usecase"
+	constant "product/infrastructure/constans"
 	"product/infrastructure/log"
 	"product/models"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -35,11 +38,19 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		})
 		return
 	}
-	product, err := h.ProductUseCase.GetProductById(c.Request.Context(), productIdConvert)
+	currency := strings.ToUpper(c.Query("currency"))
+	product, err := h.ProductUseCase.GetProductById(c.Request.Context(), productIdConvert, currency)
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
 			"productId": productId,
+			"currency":  currency,
 		}).Errorf("h.ProductUseCase.GetProductById got error :%v", err)
+		if isCurrencyError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error_message": err,
 		})
@@ -60,6 +71,47 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	})
 }
 
+const maxBatchProductIDs = 200
+
+func (h *ProductHandler) GetProductsBatch(c *gin.Context) {
+	var param models.GetProductsBatchParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid Input",
+		})
+		return
+	}
+	if len(param.IDs) == 0 || len(param.IDs) > maxBatchProductIDs {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": fmt.Sprintf("ids must contain between 1 and %d product ids", maxBatchProductIDs),
+		})
+		return
+	}
+
+	products, missingIDs, err := h.ProductUseCase.GetProductsByIDs(c.Request.Context(), param.IDs, strings.ToUpper(param.Currency))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUseCase.GetProductsByIDs got error :%v", err)
+		if isCurrencyError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": "Internal Server Error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Succesfully get Products",
+		"products":    products,
+		"missing_ids": missingIDs,
+	})
+}
+
 func (h *ProductHandler) GetProductCategory(c *gin.Context) {
 	productCategoryId := c.Param("id")
 
@@ -133,7 +185,7 @@ func (h *ProductHandler) ProductManagement(c *gin.Context) {
 				"param": param,
 			}).Errorf("h.ProductUseCase.CreateProductCategory got error : %v ", err)
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error_message": err,
+				"error_message": err.Error(),
 			})
 			return
 		}
@@ -157,7 +209,7 @@ func (h *ProductHandler) ProductManagement(c *gin.Context) {
 				"param": param,
 			}).Errorf("h.ProductUseCase.UpdateProduct got error: %v", err)
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error_message": err,
+				"error_message": err.Error(),
 			})
 			return
 		}
@@ -305,29 +357,38 @@ func (h *ProductHandler) SearchProduct(c *gin.Context) {
 	name := c.Query("name")
 	category := c.Query("category")
 
-	minPriceStr, _ := strconv.ParseFloat(c.Query("minPrice"), 64)
-	maxPriceStr, _ := strconv.ParseFloat(c.Query("maxPrice"), 64)
+	// price filters are in minor units, like the product price amount
+	minPrice, _ := strconv.ParseInt(c.Query("minPrice"), 10, 64)
+	maxPrice, _ := strconv.ParseInt(c.Query("maxPrice"), 10, 64)
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
 
 	orderBy := c.Query("orderBy")
 	sort := c.Query("sort")
+	currency := strings.ToUpper(c.Query("currency"))
 
 	products, totalCount, err := h.ProductUseCase.SearchProduct(c.Request.Context(), models.SearchProductParameter{
 		Name:     name,
 		Category: category,
-		MinPrice: int(minPriceStr),
-		MaxPrice: int(maxPriceStr),
+		MinPrice: minPrice,
+		MaxPrice: maxPrice,
 		Page:     page,
 		PageSize: pageSize,
 		OrderBy:  orderBy,
 		Sort:     sort,
+		Currency: currency,
 	})
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
 			"param": c.Params,
 		}).Errorf("h.ProductUseCase.SearchProduct got error : %v", err)
+		if isCurrencyError(err) || errors.Is(err, constant.ErrInvalidSortKey) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error_message": err,
 		})
@@ -338,8 +399,8 @@ func (h *ProductHandler) SearchProduct(c *gin.Context) {
 
 	var nextPageUrl *string
 	if page < totalPages {
-		url := fmt.Sprintf("/products/search?name=%s&category=%s&minPrice=%f&maxPrice=%f&page=%d&pageSize=%d",
-			c.Request.Host, name, category, minPriceStr, maxPriceStr, page+1, pageSize)
+		url := fmt.Sprintf("/products/search?name=%s&category=%s&minPrice=%d&maxPrice=%d&currency=%s&page=%d&pageSize=%d",
+			name, category, minPrice, maxPrice, currency, page+1, pageSize)
 		nextPageUrl = &url
 	}
 
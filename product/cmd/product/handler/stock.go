@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	constant "product/infrastructure/constans"
+	"product/infrastructure/log"
+	"product/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *ProductHandler) ReserveStock(c *gin.Context) {
+	var param models.ReserveStockParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid Input",
+		})
+		return
+	}
+
+	err := h.ProductUseCase.ReserveStock(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUseCase.ReserveStock got error : %v", err)
+		switch {
+		case errors.Is(err, constant.ErrInsufficientStock):
+			c.JSON(http.StatusConflict, gin.H{
+				"error_message": err.Error(),
+			})
+		case errors.Is(err, constant.ErrInvalidStockReservation):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error_message": "Internal Server Error",
+			})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Successfully reserve stock",
+		"reservation_id": param.ReservationID,
+	})
+}
+
+func (h *ProductHandler) CommitStock(c *gin.Context) {
+	var param models.StockReservationParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid Input",
+		})
+		return
+	}
+
+	err := h.ProductUseCase.CommitStock(c.Request.Context(), param.ReservationID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUseCase.CommitStock got error : %v", err)
+		if errors.Is(err, constant.ErrStockReservationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": "Internal Server Error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Successfully commit stock",
+		"reservation_id": param.ReservationID,
+	})
+}
+
+func (h *ProductHandler) ReleaseStock(c *gin.Context) {
+	var param models.StockReservationParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid Input",
+		})
+		return
+	}
+
+	err := h.ProductUseCase.ReleaseStock(c.Request.Context(), param.ReservationID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUseCase.ReleaseStock got error : %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": "Internal Server Error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Successfully release stock",
+		"reservation_id": param.ReservationID,
+	})
+}
+
+func (h *ProductHandler) RestockItems(c *gin.Context) {
+	var param models.RestockParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid Input",
+		})
+		return
+	}
+
+	err := h.ProductUseCase.RestockItems(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUseCase.RestockItems got error : %v", err)
+		if errors.Is(err, constant.ErrInvalidStockReservation) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": "Internal Server Error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Successfully restock items",
+		"restock_id": param.RestockID,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	constant "product/infrastructure/constans"
 	"product/models"
 
 	"gorm.io/gorm"
@@ -30,6 +31,19 @@ func (r *ProductRepository) FindProductId(ctx context.Context, productId int64)
 	return &product, nil
 }
 
+func (r *ProductRepository) FindProductsByIDs(ctx context.Context, productIDs []int64) ([]models.Product, error) {
+	var products []models.Product
+	err := r.Database.WithContext(ctx).Table("product").
+		Select("product.*, product_category.name AS category_name").
+		Joins("LEFT JOIN product_category ON product.category_id = product_category.id").
+		Where("product.id IN ?", productIDs).
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
 func (r *ProductRepository) FindProductCategory(ctx context.Context, productCategoryID int) (*models.ProductCategory, error) {
 	var productCategory models.ProductCategory
 	err := r.Database.WithContext(ctx).Table("product_category").Where("id = ?", productCategoryID).Last(&productCategory).Error
@@ -87,12 +101,20 @@ func (r *ProductRepository) DeleteProductCategory(ctx context.Context, productCa
 	return nil
 }
 
+// searchSortColumns maps the orderBy values SearchProduct accepts to the columns they sort on.
+var searchSortColumns = map[string]string{
+	"name":     "product.name",
+	"price":    "product.price_amount",
+	"stock":    "product.stock",
+	"category": "product_category.name",
+}
+
 func (r *ProductRepository) SearchProduct(ctx context.Context, params models.SearchProductParameter) ([]models.Product, int, error) {
 	var products []models.Product
 	var totalCount int64
 
 	query := r.Database.WithContext(ctx).Table("product").
-		Select("product.id, product.name, product.description, product.price, product.stock, product.category_id, product_category.name As category").
+		Select("product.id, product.sku, product.name, product.description, product.price_amount, product.price_currency, product.stock, product.category_id, product_category.name AS category_name").
 		Joins("JOIN product_category ON product.category_id = product_category.id")
 
 	//FILTERING
@@ -104,23 +126,28 @@ func (r *ProductRepository) SearchProduct(ctx context.Context, params models.Sea
 		query = query.Where("product_category.name = ?", params.Category)
 	}
 	if params.MinPrice > 0 {
-		query = query.Where("product.price >= ?", params.MinPrice)
+		query = query.Where("product.price_amount >= ?", params.MinPrice)
 	}
 	if params.MaxPrice > 0 {
-		query = query.Where("product.price <= ?", params.MaxPrice)
+		query = query.Where("product.price_amount <= ?", params.MaxPrice)
 	}
 
 	//getting total count
 	query.Model(&models.Product{}).Count(&totalCount)
 
 	if params.OrderBy == "" {
-		params.OrderBy = "product.name"
+		params.OrderBy = "name"
+	}
+	column, ok := searchSortColumns[params.OrderBy]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %s", constant.ErrInvalidSortKey, params.OrderBy)
 	}
 
 	if params.Sort == "" || (params.Sort != "ASC" && params.Sort != "DESC") {
 		params.Sort = "ASC"
 	}
-	orderBy := fmt.Sprintf("%s %s", params.OrderBy, params.Sort)
+	// product.id breaks ties, so pages don't overlap
+	orderBy := fmt.Sprintf("%s %s, product.id", column, params.Sort)
 	query = query.Order(orderBy)
 
 	offset := (params.Page - 1) * params.PageSize
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"product/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var cacheKeyExchangeRate = "exchange_rate:%s"
+
+// FindExchangeRate returns the exchange rate of currency, or nil when there is none.
+func (r *ProductRepository) FindExchangeRate(ctx context.Context, currency string) (*models.ExchangeRate, error) {
+	var exchangeRate models.ExchangeRate
+	err := r.Database.WithContext(ctx).Table("exchange_rate").Where("currency = ?", currency).First(&exchangeRate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &exchangeRate, nil
+}
+
+func (r *ProductRepository) FindExchangeRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	var exchangeRates []models.ExchangeRate
+	err := r.Database.WithContext(ctx).Table("exchange_rate").Order("currency").Find(&exchangeRates).Error
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRates, nil
+}
+
+func (r *ProductRepository) UpsertExchangeRate(ctx context.Context, exchangeRate *models.ExchangeRate) error {
+	err := r.Database.WithContext(ctx).Table("exchange_rate").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "update_time"}),
+	}).Create(exchangeRate).Error
+	return err
+}
+
+func (r *ProductRepository) GetExchangeRateFromRedis(ctx context.Context, currency string) (*models.ExchangeRate, error) {
+	var exchangeRate models.ExchangeRate
+	exchangeRateStr, err := r.Redis.Get(ctx, fmt.Sprintf(cacheKeyExchangeRate, currency)).Result()
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(exchangeRateStr), &exchangeRate)
+	if err != nil {
+		return nil, err
+	}
+	return &exchangeRate, nil
+}
+
+func (r *ProductRepository) SetExchangeRate(ctx context.Context, exchangeRate *models.ExchangeRate) error {
+	exchangeRateJSON, err := json.Marshal(exchangeRate)
+	if err != nil {
+		return err
+	}
+	return r.Redis.SetEx(ctx, fmt.Sprintf(cacheKeyExchangeRate, exchangeRate.Currency), exchangeRateJSON, 10*time.Minute).Err()
+}
+
+func (r *ProductRepository) DeleteExchangeRate(ctx context.Context, currency string) error {
+	return r.Redis.Del(ctx, fmt.Sprintf(cacheKeyExchangeRate, currency)).Err()
+}
@@ -28,6 +28,34 @@ func (r *ProductRepository) GetProductByIDFromRedis(ctx context.Context, product
 	return &product, nil
 }
 
+// GetProductsByIDsFromRedis reads all cached products in one MGET. IDs that aren't cached are
+// missing from the result.
+func (r *ProductRepository) GetProductsByIDsFromRedis(ctx context.Context, productIDs []int64) (map[int64]*models.Product, error) {
+	cacheKeys := make([]string, len(productIDs))
+	for i, productID := range productIDs {
+		cacheKeys[i] = fmt.Sprintf(cacheKeyProductInfo, productID)
+	}
+	values, err := r.Redis.MGet(ctx, cacheKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int64]*models.Product, len(productIDs))
+	for i, value := range values {
+		productStr, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var product models.Product
+		err = json.Unmarshal([]byte(productStr), &product)
+		if err != nil {
+			return nil, err
+		}
+		products[productIDs[i]] = &product
+	}
+	return products, nil
+}
+
 func (r *ProductRepository) GetProductCategoryByIDFromRedis(ctx context.Context, productCategoryID int64) (*models.ProductCategory, error) {
 	cacheKey := fmt.Sprintf(cacheKeyProductCategoryInfo, productCategoryID)
 	var productCategory models.ProductCategory
@@ -55,6 +83,22 @@ func (r *ProductRepository) SetProductByID(ctx context.Context, product *models.
 	return nil
 }
 
+func (r *ProductRepository) SetProductsByIDs(ctx context.Context, products []models.Product) error {
+	pipe := r.Redis.Pipeline()
+	for _, product := range products {
+		productJSON, err := json.Marshal(product)
+		if err != nil {
+			return err
+		}
+		pipe.SetEx(ctx, fmt.Sprintf(cacheKeyProductInfo, product.ID), productJSON, 10*time.Minute)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *ProductRepository) SetProductCategoryByID(ctx context.Context, product *models.ProductCategory, productCategoryID int64) error {
 	cacheKey := fmt.Sprintf(cacheKeyProductCategoryInfo, productCategoryID)
 	productJSON, err := json.Marshal(product)
@@ -67,3 +111,12 @@ func (r *ProductRepository) SetProductCategoryByID(ctx context.Context, product
 	}
 	return nil
 }
+
+func (r *ProductRepository) DeleteProductByID(ctx context.Context, productID int64) error {
+	cacheKey := fmt.Sprintf(cacheKeyProductInfo, productID)
+	err := r.Redis.Del(ctx, cacheKey).Err()
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	constant "product/infrastructure/constans"
+	"product/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *ProductRepository) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	tx := r.Database.Begin().WithContext(ctx)
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// LockStockReservationTx serialises the transactions on reservationID until tx ends, the first one
+// included, which finds no reservation row to lock yet.
+func (r *ProductRepository) LockStockReservationTx(ctx context.Context, tx *gorm.DB, reservationID string) error {
+	err := tx.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", reservationID).Error
+	return err
+}
+
+func (r *ProductRepository) FindStockReservationForUpdateTx(ctx context.Context, tx *gorm.DB, reservationID string) ([]models.StockReservation, error) {
+	var reservations []models.StockReservation
+	err := tx.WithContext(ctx).Table("stock_reservation").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("reservation_id = ?", reservationID).Order("product_id").Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// FindStockRestockForUpdateTx locks the products already put back under restockID. The first
+// restock of an ID finds nothing to lock, so concurrent calls are serialised by the unique
+// constraint on insert instead.
+func (r *ProductRepository) FindStockRestockForUpdateTx(ctx context.Context, tx *gorm.DB, restockID string) ([]models.StockRestock, error) {
+	var restocks []models.StockRestock
+	err := tx.WithContext(ctx).Table("stock_restock").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("restock_id = ?", restockID).Order("product_id").Find(&restocks).Error
+	if err != nil {
+		return nil, err
+	}
+	return restocks, nil
+}
+
+func (r *ProductRepository) InsertStockRestocksTx(ctx context.Context, tx *gorm.DB, restocks []models.StockRestock) error {
+	err := tx.WithContext(ctx).Table("stock_restock").Create(&restocks).Error
+	return err
+}
+
+func (r *ProductRepository) InsertStockReservationsTx(ctx context.Context, tx *gorm.DB, reservations []models.StockReservation) error {
+	err := tx.WithContext(ctx).Table("stock_reservation").Create(&reservations).Error
+	return err
+}
+
+func (r *ProductRepository) UpdateStockReservationStatusTx(ctx context.Context, tx *gorm.DB, reservationID string, fromStatuses []string, toStatus string) error {
+	err := tx.WithContext(ctx).Table("stock_reservation").
+		Where("reservation_id = ? AND status IN ?", reservationID, fromStatuses).
+		Updates(map[string]interface{}{
+			"status":      toStatus,
+			"update_time": time.Now(),
+		}).Error
+	return err
+}
+
+// DecrementStockTx takes quantity out of the product stock only if enough is left, so concurrent
+// reservations can never push the stock below zero.
+func (r *ProductRepository) DecrementStockTx(ctx context.Context, tx *gorm.DB, productID int64, quantity int64) error {
+	result := tx.WithContext(ctx).Table("product").
+		Where("id = ? AND stock >= ?", productID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrInsufficientStock
+	}
+	return nil
+}
+
+func (r *ProductRepository) IncrementStockTx(ctx context.Context, tx *gorm.DB, productID int64, quantity int64) error {
+	err := tx.WithContext(ctx).Table("product").
+		Where("id = ?", productID).
+		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+	return err
+}
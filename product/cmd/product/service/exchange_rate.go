@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"product/infrastructure/log"
+	"product/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetExchangeRate reads the exchange rate of currency through the cache. It returns nil when
+// there is no rate for currency.
+func (s *ProductService) GetExchangeRate(ctx context.Context, currency string) (*models.ExchangeRate, error) {
+	//redis
+	exchangeRate, err := s.ProductRepository.GetExchangeRateFromRedis(ctx, currency)
+	if err == nil {
+		return exchangeRate, nil
+	}
+
+	//db
+	exchangeRate, err = s.ProductRepository.FindExchangeRate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	if exchangeRate == nil {
+		return nil, nil
+	}
+	err = s.ProductRepository.SetExchangeRate(ctx, exchangeRate)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"currency": currency,
+		}).Errorf("s.ProductRepository.SetExchangeRate got error: %v", err)
+	}
+	return exchangeRate, nil
+}
+
+func (s *ProductService) GetExchangeRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	exchangeRates, err := s.ProductRepository.FindExchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRates, nil
+}
+
+// SaveExchangeRate stores the rate and drops the cached one, so prices use it right away.
+func (s *ProductService) SaveExchangeRate(ctx context.Context, exchangeRate *models.ExchangeRate) error {
+	err := s.ProductRepository.UpsertExchangeRate(ctx, exchangeRate)
+	if err != nil {
+		return err
+	}
+	err = s.ProductRepository.DeleteExchangeRate(ctx, exchangeRate.Currency)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"currency": exchangeRate.Currency,
+		}).Errorf("s.ProductRepository.DeleteExchangeRate got error: %v", err)
+	}
+	return nil
+}
@@ -60,6 +60,67 @@ func (s *ProductService) GetProductById(ctx context.Context, productId int64) (*
 	return product, nil
 }
 
+// GetProductsByIDs reads products through the cache: everything cached comes from one MGET and the
+// rest from a single query, which is then cached in the background. Products are returned in the
+// order of productIDs; unknown IDs are returned separately.
+func (s *ProductService) GetProductsByIDs(ctx context.Context, productIDs []int64) ([]models.Product, []int64, error) {
+	uniqueIDs := make([]int64, 0, len(productIDs))
+	seen := make(map[int64]bool, len(productIDs))
+	for _, productID := range productIDs {
+		if !seen[productID] {
+			seen[productID] = true
+			uniqueIDs = append(uniqueIDs, productID)
+		}
+	}
+
+	//redis
+	products, err := s.ProductRepository.GetProductsByIDsFromRedis(ctx, uniqueIDs)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productIds": uniqueIDs,
+		}).Errorf("s.ProductRepository.GetProductsByIDsFromRedis got error : %v", err)
+		products = map[int64]*models.Product{}
+	}
+
+	//db
+	var uncachedIDs []int64
+	for _, productID := range uniqueIDs {
+		if products[productID] == nil {
+			uncachedIDs = append(uncachedIDs, productID)
+		}
+	}
+	if len(uncachedIDs) > 0 {
+		dbProducts, err := s.ProductRepository.FindProductsByIDs(ctx, uncachedIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range dbProducts {
+			products[dbProducts[i].ID] = &dbProducts[i]
+		}
+
+		if len(dbProducts) > 0 {
+			go func(dbProducts []models.Product) {
+				// Use background context to prevent cancellation
+				errConCurrent := s.ProductRepository.SetProductsByIDs(context.Background(), dbProducts)
+				if errConCurrent != nil {
+					log.Logger.Errorf("s.ProductRepository.SetProductsByIDs got error: %v", errConCurrent)
+				}
+			}(dbProducts)
+		}
+	}
+
+	result := make([]models.Product, 0, len(uniqueIDs))
+	missingIDs := []int64{}
+	for _, productID := range uniqueIDs {
+		if product := products[productID]; product != nil {
+			result = append(result, *product)
+		} else {
+			missingIDs = append(missingIDs, productID)
+		}
+	}
+	return result, missingIDs, nil
+}
+
 func (s *ProductService) GetProductCategoryById(ctx context.Context, productCategoryId int) (*models.ProductCategory, error) {
 	productCategory, err := s.ProductRepository.FindProductCategory(ctx, productCategoryId)
 	if err != nil {
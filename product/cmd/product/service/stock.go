@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	constant "product/infrastructure/constans"
+	"product/infrastructure/log"
+	"product/models"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ReserveStock takes the stock of every item in one transaction: either all items are reserved or
+// none are. Reserving an already known reservation ID is a no-op, so callers can safely retry, but
+// a released one is refused: the caller gave up on it and nobody would release it again.
+func (s *ProductService) ReserveStock(ctx context.Context, param *models.ReserveStockParameter) error {
+	if len(param.Items) == 0 {
+		return fmt.Errorf("%w: no items", constant.ErrInvalidStockReservation)
+	}
+	items := make([]models.StockReservationItem, len(param.Items))
+	copy(items, param.Items)
+	// always lock products in the same order so two reservations can't deadlock each other
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ProductID < items[j].ProductID
+	})
+	for i, item := range items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: invalid quantity for product %d", constant.ErrInvalidStockReservation, item.ProductID)
+		}
+		if i > 0 && items[i-1].ProductID == item.ProductID {
+			return fmt.Errorf("%w: duplicate product %d", constant.ErrInvalidStockReservation, item.ProductID)
+		}
+	}
+
+	err := s.ProductRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		err := s.ProductRepository.LockStockReservationTx(ctx, tx, param.ReservationID)
+		if err != nil {
+			return err
+		}
+		existing, err := s.ProductRepository.FindStockReservationForUpdateTx(ctx, tx, param.ReservationID)
+		if err != nil {
+			return err
+		}
+		for _, reservation := range existing {
+			if reservation.Status == constant.StockReservationStatusReleased {
+				return fmt.Errorf("%w: reservation %s has been released", constant.ErrInvalidStockReservation, param.ReservationID)
+			}
+		}
+		if len(existing) > 0 {
+			return nil
+		}
+
+		reservations := make([]models.StockReservation, 0, len(items))
+		for _, item := range items {
+			err = s.ProductRepository.DecrementStockTx(ctx, tx, item.ProductID, item.Quantity)
+			if err != nil {
+				if errors.Is(err, constant.ErrInsufficientStock) {
+					return fmt.Errorf("%w for product %d", err, item.ProductID)
+				}
+				return err
+			}
+			reservations = append(reservations, models.StockReservation{
+				ReservationID: param.ReservationID,
+				ProductID:     item.ProductID,
+				Quantity:      item.Quantity,
+				Status:        constant.StockReservationStatusReserved,
+			})
+		}
+		return s.ProductRepository.InsertStockReservationsTx(ctx, tx, reservations)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		s.invalidateProductCache(ctx, item.ProductID)
+	}
+	return nil
+}
+
+// CommitStock marks a reservation as final once the order that holds it has been placed.
+func (s *ProductService) CommitStock(ctx context.Context, reservationID string) error {
+	err := s.ProductRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		reservations, err := s.ProductRepository.FindStockReservationForUpdateTx(ctx, tx, reservationID)
+		if err != nil {
+			return err
+		}
+		if len(reservations) == 0 {
+			return constant.ErrStockReservationNotFound
+		}
+		return s.ProductRepository.UpdateStockReservationStatusTx(ctx, tx, reservationID,
+			[]string{constant.StockReservationStatusReserved}, constant.StockReservationStatusCommitted)
+	})
+	return err
+}
+
+// ReleaseStock puts reserved or committed stock back on the shelf. Releasing an already released
+// reservation is a no-op. Releasing an unknown one records it as released, so a reserve that was
+// given up on by its caller and lands late is refused instead of holding the stock for good.
+func (s *ProductService) ReleaseStock(ctx context.Context, reservationID string) error {
+	var released []models.StockReservation
+
+	err := s.ProductRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		err := s.ProductRepository.LockStockReservationTx(ctx, tx, reservationID)
+		if err != nil {
+			return err
+		}
+		reservations, err := s.ProductRepository.FindStockReservationForUpdateTx(ctx, tx, reservationID)
+		if err != nil {
+			return err
+		}
+		if len(reservations) == 0 {
+			// no product and nothing to put back, only the marker of the release
+			return s.ProductRepository.InsertStockReservationsTx(ctx, tx, []models.StockReservation{{
+				ReservationID: reservationID,
+				Status:        constant.StockReservationStatusReleased,
+			}})
+		}
+		for _, reservation := range reservations {
+			if reservation.Status == constant.StockReservationStatusReleased {
+				continue
+			}
+			err = s.ProductRepository.IncrementStockTx(ctx, tx, reservation.ProductID, reservation.Quantity)
+			if err != nil {
+				return err
+			}
+			released = append(released, reservation)
+		}
+		return s.ProductRepository.UpdateStockReservationStatusTx(ctx, tx, reservationID,
+			[]string{constant.StockReservationStatusReserved, constant.StockReservationStatusCommitted},
+			constant.StockReservationStatusReleased)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range released {
+		s.invalidateProductCache(ctx, reservation.ProductID)
+	}
+	return nil
+}
+
+// RestockItems puts returned items back on the shelf. Restocking an already known restock ID is a
+// no-op, so callers can safely retry.
+func (s *ProductService) RestockItems(ctx context.Context, param *models.RestockParameter) error {
+	if len(param.Items) == 0 {
+		return fmt.Errorf("%w: no items", constant.ErrInvalidStockReservation)
+	}
+	items := make([]models.StockReservationItem, len(param.Items))
+	copy(items, param.Items)
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ProductID < items[j].ProductID
+	})
+	for i, item := range items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: invalid quantity for product %d", constant.ErrInvalidStockReservation, item.ProductID)
+		}
+		if i > 0 && items[i-1].ProductID == item.ProductID {
+			return fmt.Errorf("%w: duplicate product %d", constant.ErrInvalidStockReservation, item.ProductID)
+		}
+	}
+
+	restocked := false
+	err := s.ProductRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		existing, err := s.ProductRepository.FindStockRestockForUpdateTx(ctx, tx, param.RestockID)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return nil
+		}
+
+		restocks := make([]models.StockRestock, 0, len(items))
+		for _, item := range items {
+			err = s.ProductRepository.IncrementStockTx(ctx, tx, item.ProductID, item.Quantity)
+			if err != nil {
+				return err
+			}
+			restocks = append(restocks, models.StockRestock{
+				RestockID: param.RestockID,
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+			})
+		}
+		restocked = true
+		return s.ProductRepository.InsertStockRestocksTx(ctx, tx, restocks)
+	})
+	if err != nil {
+		return err
+	}
+
+	if restocked {
+		for _, item := range items {
+			s.invalidateProductCache(ctx, item.ProductID)
+		}
+	}
+	return nil
+}
+
+func (s *ProductService) invalidateProductCache(ctx context.Context, productID int64) {
+	err := s.ProductRepository.DeleteProductByID(ctx, productID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productId": productID,
+		}).Errorf("s.ProductRepository.DeleteProductByID got error: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"money"
+	constant "product/infrastructure/constans"
+	"product/models"
+	"strings"
+	"time"
+)
+
+func (uc *ProductUseCase) GetExchangeRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	exchangeRates, err := uc.ProductService.GetExchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRates, nil
+}
+
+// SetExchangeRate creates or replaces the rate from the base currency to param.Currency.
+func (uc *ProductUseCase) SetExchangeRate(ctx context.Context, param *models.ExchangeRate) error {
+	param.Currency = strings.ToUpper(param.Currency)
+	if !money.IsValidCurrency(param.Currency) || param.Currency == uc.BaseCurrency {
+		return fmt.Errorf("%w: %q", constant.ErrInvalidCurrency, param.Currency)
+	}
+	_, err := money.ParseRate(param.Rate)
+	if err != nil {
+		return err
+	}
+	param.UpdateTime = time.Now()
+	return uc.ProductService.SaveExchangeRate(ctx, param)
+}
+
+// exchangeRate returns the rate from the base currency to currency.
+func (uc *ProductUseCase) exchangeRate(ctx context.Context, currency string) (string, error) {
+	if !money.IsValidCurrency(currency) {
+		return "", fmt.Errorf("%w: %q", constant.ErrInvalidCurrency, currency)
+	}
+	exchangeRate, err := uc.ProductService.GetExchangeRate(ctx, currency)
+	if err != nil {
+		return "", err
+	}
+	if exchangeRate == nil {
+		return "", fmt.Errorf("%w: %s to %s", constant.ErrExchangeRateNotFound, uc.BaseCurrency, currency)
+	}
+	return exchangeRate.Rate, nil
+}
+
+// convertPrices prices products in currency and keeps the base price and rate used on each of
+// them. Products are left as they are when currency is empty or the base currency.
+func (uc *ProductUseCase) convertPrices(ctx context.Context, products []models.Product, currency string) error {
+	currency = strings.ToUpper(currency)
+	if currency == "" || currency == uc.BaseCurrency || len(products) == 0 {
+		return nil
+	}
+	rate, err := uc.exchangeRate(ctx, currency)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		basePrice := products[i].Price
+		products[i].Price, err = basePrice.Convert(currency, rate)
+		if err != nil {
+			return err
+		}
+		products[i].BasePrice = &basePrice
+		products[i].ExchangeRate = rate
+	}
+	return nil
+}
+
+// toBaseAmount converts a price filter given in minor units of currency into the base currency.
+func (uc *ProductUseCase) toBaseAmount(amount int64, currency string, rate string) (int64, error) {
+	if amount <= 0 {
+		return amount, nil
+	}
+	baseAmount, err := money.New(amount, currency).ConvertInverse(uc.BaseCurrency, rate)
+	if err != nil {
+		return 0, err
+	}
+	return baseAmount.Amount, nil
+}
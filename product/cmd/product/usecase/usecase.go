@@ -3,7 +3,10 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"money"
 	"product/cmd/product/service"
+	constant "product/infrastructure/constans"
 	"product/infrastructure/log"
 	"product/models"
 
@@ -13,20 +16,48 @@ import (
 
 type ProductUseCase struct {
 	ProductService service.ProductService
+	BaseCurrency   string
 }
 
-func NewProductUseCase(productService service.ProductService) *ProductUseCase {
+func NewProductUseCase(productService service.ProductService, baseCurrency string) *ProductUseCase {
+	if baseCurrency == "" {
+		baseCurrency = money.DefaultCurrency
+	}
 	return &ProductUseCase{
 		ProductService: productService,
+		BaseCurrency:   baseCurrency,
 	}
 }
 
-func (uc *ProductUseCase) GetProductById(ctx context.Context, productId int64) (*models.Product, error) {
+// GetProductById returns the product, priced in currency when it is set.
+func (uc *ProductUseCase) GetProductById(ctx context.Context, productId int64, currency string) (*models.Product, error) {
 	product, err := uc.ProductService.GetProductById(ctx, productId)
 	if err != nil {
 		return nil, err
 	}
-	return product, nil
+	if product.ID == 0 {
+		return product, nil
+	}
+	// the service may still be caching this product, convert a copy
+	converted := []models.Product{*product}
+	err = uc.convertPrices(ctx, converted, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &converted[0], nil
+}
+
+// GetProductsByIDs returns the products, priced in currency when it is set.
+func (uc *ProductUseCase) GetProductsByIDs(ctx context.Context, productIDs []int64, currency string) ([]models.Product, []int64, error) {
+	products, missingIDs, err := uc.ProductService.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = uc.convertPrices(ctx, products, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+	return products, missingIDs, nil
 }
 
 func (uc *ProductUseCase) GetProductCategoryById(ctx context.Context, productCategoryId int) (*models.ProductCategory, error) {
@@ -41,6 +72,10 @@ func (uc *ProductUseCase) GetProductCategoryById(ctx context.Context, productCat
 }
 
 func (uc *ProductUseCase) CreateProduct(ctx context.Context, param *models.Product) (int64, error) {
+	err := uc.validatePrice(&param.Price)
+	if err != nil {
+		return 0, err
+	}
 	productId, err := uc.ProductService.ProductRepository.InsertNewProduct(ctx, param)
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
@@ -64,7 +99,11 @@ func (uc *ProductUseCase) CreateProductCategory(ctx context.Context, param *mode
 }
 
 func (uc *ProductUseCase) UpdateProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
-	product, err := uc.ProductService.ProductRepository.UpdateProduct(ctx, product)
+	err := uc.validatePrice(&product.Price)
+	if err != nil {
+		return nil, err
+	}
+	product, err = uc.ProductService.ProductRepository.UpdateProduct(ctx, product)
 	if err != nil {
 		return nil, err
 	}
@@ -95,11 +134,81 @@ func (uc *ProductUseCase) DeleteProductCategory(ctx context.Context, productCate
 	return nil
 }
 
+// SearchProduct searches products priced in param.Currency when it is set; the price filters are
+// then in that currency as well.
 func (uc *ProductUseCase) SearchProduct(ctx context.Context, param models.SearchProductParameter) ([]models.Product, int, error) {
+	if param.Currency != "" && param.Currency != uc.BaseCurrency {
+		rate, err := uc.exchangeRate(ctx, param.Currency)
+		if err != nil {
+			return nil, 0, err
+		}
+		param.MinPrice, err = uc.toBaseAmount(param.MinPrice, param.Currency, rate)
+		if err != nil {
+			return nil, 0, err
+		}
+		param.MaxPrice, err = uc.toBaseAmount(param.MaxPrice, param.Currency, rate)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
 	product, totalCount, err := uc.ProductService.SearchProduct(ctx, param)
 
+	if err != nil {
+		return nil, 0, err
+	}
+	err = uc.convertPrices(ctx, product, param.Currency)
 	if err != nil {
 		return nil, 0, err
 	}
 	return product, totalCount, nil
 }
+
+func (uc *ProductUseCase) ReserveStock(ctx context.Context, param *models.ReserveStockParameter) error {
+	err := uc.ProductService.ReserveStock(ctx, param)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (uc *ProductUseCase) CommitStock(ctx context.Context, reservationID string) error {
+	err := uc.ProductService.CommitStock(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (uc *ProductUseCase) ReleaseStock(ctx context.Context, reservationID string) error {
+	err := uc.ProductService.ReleaseStock(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (uc *ProductUseCase) RestockItems(ctx context.Context, param *models.RestockParameter) error {
+	err := uc.ProductService.RestockItems(ctx, param)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// validatePrice defaults a price without a currency to the base currency and rejects prices in
+// other currencies and negative amounts.
+func (uc *ProductUseCase) validatePrice(price *money.Money) error {
+	if price.Currency == "" {
+		price.Currency = uc.BaseCurrency
+	}
+	*price = money.New(price.Amount, price.Currency)
+	err := price.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %v", constant.ErrInvalidPrice, err)
+	}
+	if price.Currency != uc.BaseCurrency {
+		return fmt.Errorf("%w: prices are kept in %s, got %s", constant.ErrInvalidPrice, uc.BaseCurrency, price.Currency)
+	}
+	return nil
+}
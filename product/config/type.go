@@ -1,10 +1,14 @@
 package config
 
+import "time"
+
 type Config struct {
-	App      AppConfig      `yaml:"app" validate:"required"`
-	Database DatabaseConfig `yaml:"database" validate:"required"`
-	Redis    RedisConfig    `yaml:"redis" validate:"required"`
-	Secret   SecretConfig   `yaml:"secret" validate:"required"`
+	App         AppConfig         `yaml:"app" validate:"required"`
+	Database    DatabaseConfig    `yaml:"database" validate:"required"`
+	Redis       RedisConfig       `yaml:"redis" validate:"required"`
+	Secret      SecretConfig      `yaml:"secret" validate:"required"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Pricing     PricingConfig     `yaml:"pricing"`
 }
 
 type AppConfig struct {
@@ -29,3 +33,14 @@ type RedisConfig struct {
 type SecretConfig struct {
 	JWTSecret string `yaml:"jwt_secret" validate:"required"`
 }
+
+type IdempotencyConfig struct {
+	TTL     time.Duration `yaml:"ttl"`
+	LockTTL time.Duration `yaml:"lock_ttl" mapstructure:"lock_ttl"`
+}
+
+type PricingConfig struct {
+	// BaseCurrency is the currency product prices are kept in, other currencies are converted
+	// with the exchange_rate table
+	BaseCurrency string `yaml:"base_currency" mapstructure:"base_currency"`
+}
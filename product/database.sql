@@ -5,10 +5,41 @@ create table product_category(
 
 create table product (
     id bigserial PRIMARY KEY,
+    sku varchar(64) not null default '',
     name varchar(255) not null,
     description text,
-    price numeric not null,
+    price_amount bigint not null check (price_amount >= 0),
+    price_currency char(3) not null default 'USD',
     stock integer not null,
     category_id integer not null,
     constraint fk_category foreign key (category_id) REFERENCES product_category(id) ON DELETE CASCADE
 );
+
+create unique index uq_product_sku on product (sku) where sku <> '';
+
+create table stock_reservation (
+    id bigserial primary key,
+    reservation_id varchar(64) not null,
+    product_id bigint not null,
+    -- a release of an unknown reservation leaves a marker without product nor quantity
+    quantity integer not null check (quantity > 0 or status = 'released'),
+    status varchar(20) not null,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp,
+    constraint uq_stock_reservation unique (reservation_id, product_id)
+);
+
+create table stock_restock (
+    id bigserial primary key,
+    restock_id varchar(64) not null,
+    product_id bigint not null,
+    quantity integer not null check (quantity > 0),
+    create_time timestamp default current_timestamp,
+    constraint uq_stock_restock unique (restock_id, product_id)
+);
+
+create table exchange_rate (
+    currency char(3) primary key,
+    rate numeric(20, 10) not null check (rate > 0),
+    update_time timestamp default current_timestamp
+);
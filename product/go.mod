@@ -4,6 +4,7 @@ go 1.24.5
 
 require (
 	github.com/gin-gonic/gin v1.10.1
+	github.com/golang-jwt/jwt/v5 v5.3.0
 	github.com/google/uuid v1.6.0
 	github.com/redis/go-redis/v9 v9.14.0
 	github.com/sirupsen/logrus v1.9.3
@@ -11,6 +12,8 @@ require (
 	golang.org/x/net v0.25.0
 	gorm.io/driver/postgres v1.6.0
 	gorm.io/gorm v1.31.0
+	idempotency v0.0.0
+	money v0.0.0
 )
 
 require (
@@ -58,3 +61,8 @@ require (
 	google.golang.org/protobuf v1.34.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace (
+	idempotency => ../idempotency
+	money => ../money
+)
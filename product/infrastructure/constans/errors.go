@@ -0,0 +1,13 @@
+package constant
+
+import "errors"
+
+var (
+	ErrInsufficientStock        = errors.New("insufficient stock")
+	ErrStockReservationNotFound = errors.New("stock reservation not found")
+	ErrInvalidStockReservation  = errors.New("invalid stock reservation")
+	ErrInvalidPrice             = errors.New("invalid price")
+	ErrInvalidCurrency          = errors.New("invalid currency")
+	ErrExchangeRateNotFound     = errors.New("exchange rate not found")
+	ErrInvalidSortKey           = errors.New("invalid sort key")
+)
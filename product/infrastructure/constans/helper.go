@@ -0,0 +1,13 @@
+package constant
+
+const (
+	StockReservationStatusReserved  = "reserved"
+	StockReservationStatusCommitted = "committed"
+	StockReservationStatusReleased  = "released"
+)
+
+const (
+	RoleAdmin = "admin"
+	// RoleService is carried by the tokens the other services sign to call the internal endpoints
+	RoleService = "service"
+)
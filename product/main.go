@@ -22,12 +22,12 @@ func main() {
 
 	productRepository := repository.NewProductRepository(db, redis)
 	productService := service.NewProductRepository(*productRepository)
-	productUseCase := usecase.NewProductUseCase(*productService)
+	productUseCase := usecase.NewProductUseCase(*productService, cfg.Pricing.BaseCurrency)
 	productHandler := handler.NewProductHandler(productUseCase)
 	port := cfg.App.Port
 	router := gin.Default()
 
-	routes.SetupRouter(router, *productHandler)
+	routes.SetupRouter(router, *productHandler, redis, cfg.Idempotency, cfg.Secret.JWTSecret)
 
 	router.Run(":" + port)
 	println("Starting server on port:", port)
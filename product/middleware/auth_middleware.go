@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	constant "product/infrastructure/constans"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthMiddleware checks the bearer token issued by the user service and puts its user_id and role
+// in the context.
+func AuthMiddleware(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error_message": "missing required auth",
+			})
+			c.Abort()
+			return
+		}
+		//format token
+		//authorization: bearer xxx
+		tokenString := strings.Split(authHeader, " ")
+		if len(tokenString) != 2 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error_message": "invalid token",
+			})
+			c.Abort()
+			return
+		}
+
+		token, err := jwt.Parse(tokenString[1], func(token *jwt.Token) (interface{}, error) {
+			return []byte(secret), nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+		if err != nil || !token.Valid {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error_message": "invalid token",
+			})
+			c.Abort()
+			return
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error_message": "invalid token",
+			})
+			c.Abort()
+			return
+		}
+		if userID, ok := claims["user_id"].(float64); ok {
+			c.Set("user_id", userID)
+		}
+		if role, ok := claims["role"].(string); ok {
+			c.Set("role", role)
+		}
+		c.Next()
+	}
+}
+
+// AdminOnly lets through only users whose token carries the admin role. It must run after
+// AuthMiddleware.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != constant.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error_message": "forbidden",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ServiceOnly lets through only the other services, whose token carries the service role. It must
+// run after AuthMiddleware.
+func ServiceOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != constant.RoleService {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error_message": "forbidden",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,7 @@
+-- prices move from a numeric amount in major units to integer minor units plus a currency code.
+-- every existing price is in USD, which has two decimal places.
+alter table product add column price_currency char(3) not null default 'USD';
+
+alter table product rename column price to price_amount;
+alter table product alter column price_amount type bigint using round(price_amount * 100);
+alter table product add constraint product_price_amount_check check (price_amount >= 0);
@@ -0,0 +1,6 @@
+-- rates to convert base currency prices into the other currencies the storefront sells in
+create table exchange_rate (
+    currency char(3) primary key,
+    rate numeric(20, 10) not null check (rate > 0),
+    update_time timestamp default current_timestamp
+);
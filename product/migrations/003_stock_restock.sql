@@ -0,0 +1,9 @@
+-- returned items put back on the shelf, keyed by the caller's restock ID so a retry is a no-op
+create table stock_restock (
+    id bigserial primary key,
+    restock_id varchar(64) not null,
+    product_id bigint not null,
+    quantity integer not null check (quantity > 0),
+    create_time timestamp default current_timestamp,
+    constraint uq_stock_restock unique (restock_id, product_id)
+);
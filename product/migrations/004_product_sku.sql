@@ -0,0 +1,4 @@
+-- stock keeping unit, unique among the products that have one
+alter table product add column sku varchar(64) not null default '';
+
+create unique index uq_product_sku on product (sku) where sku <> '';
@@ -0,0 +1,11 @@
+-- stock taken by the orders being placed, keyed by the caller's reservation ID so a retry is a no-op
+create table stock_reservation (
+    id bigserial primary key,
+    reservation_id varchar(64) not null,
+    product_id bigint not null,
+    quantity integer not null check (quantity > 0),
+    status varchar(20) not null,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp,
+    constraint uq_stock_reservation unique (reservation_id, product_id)
+);
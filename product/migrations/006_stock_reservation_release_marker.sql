@@ -0,0 +1,4 @@
+-- a release of an unknown reservation leaves a marker without product nor quantity, so a reserve
+-- landing after it is refused
+alter table stock_reservation drop constraint stock_reservation_quantity_check;
+alter table stock_reservation add constraint stock_reservation_quantity_check check (quantity > 0 or status = 'released');
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// ExchangeRate is the price of one unit of the base currency in Currency, e.g. "0.92" for EUR
+// when prices are kept in USD.
+type ExchangeRate struct {
+	Currency   string    `json:"currency" gorm:"primaryKey"`
+	Rate       string    `json:"rate"`
+	UpdateTime time.Time `json:"update_time"`
+}
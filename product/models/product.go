@@ -1,12 +1,21 @@
 package models
 
+import "money"
+
 type Product struct {
-	ID          int64  `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Price       int64  `json:"price"`
-	Stock       int64  `json:"stock"`
-	CategoryId  int64  `json:"category_id"`
+	ID          int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	SKU         string      `json:"sku" gorm:"column:sku"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	Stock       int64       `json:"stock"`
+	CategoryId  int64       `json:"category_id"`
+	// CategoryName is read along with the product, it is written through the category
+	CategoryName string `json:"category_name" gorm:"->"`
+
+	// BasePrice and ExchangeRate are set when Price was converted to a requested currency
+	BasePrice    *money.Money `json:"base_price,omitempty" gorm:"-"`
+	ExchangeRate string       `json:"exchange_rate,omitempty" gorm:"-"`
 }
 
 type ProductCategory struct {
@@ -27,8 +36,9 @@ type ProductManagementParameter struct {
 type SearchProductParameter struct {
 	Name     string `json:"name"`
 	Category string `json:"category"`
-	MinPrice int    `json:"minPrice"`
-	MaxPrice int    `json:"maxPrice"`
+	MinPrice int64  `json:"minPrice"`
+	MaxPrice int64  `json:"maxPrice"`
+	Currency string `json:"currency"`
 	Page     int    `json:"page"`
 	PageSize int    `json:"pageSize"`
 	OrderBy  string `json:"orderBy"`
@@ -43,3 +53,13 @@ type SearchProductResponse struct {
 	TotalPages  int       `json:"totalPages"`
 	NextPageUrl *string   `json:"nextPageUrl"`
 }
+
+type GetProductsBatchParameter struct {
+	IDs      []int64 `json:"ids" binding:"required"`
+	Currency string  `json:"currency"`
+}
+
+type GetProductsBatchResponse struct {
+	Products   []Product `json:"products"`
+	MissingIDs []int64   `json:"missing_ids"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+type StockReservation struct {
+	ID            int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ReservationID string    `json:"reservation_id"`
+	ProductID     int64     `json:"product_id"`
+	Quantity      int64     `json:"quantity"`
+	Status        string    `json:"status"`
+	CreateTime    time.Time `json:"create_time" gorm:"autoCreateTime"`
+	UpdateTime    time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+type StockReservationItem struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+}
+
+type ReserveStockParameter struct {
+	ReservationID string                 `json:"reservation_id" binding:"required"`
+	Items         []StockReservationItem `json:"items" binding:"required"`
+}
+
+type StockReservationParameter struct {
+	ReservationID string `json:"reservation_id" binding:"required"`
+}
+
+// StockRestock records stock put back on the shelf, e.g. returned items, so a restock ID is only
+// ever applied once.
+type StockRestock struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	RestockID  string    `json:"restock_id"`
+	ProductID  int64     `json:"product_id"`
+	Quantity   int64     `json:"quantity"`
+	CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+}
+
+type RestockParameter struct {
+	RestockID string                 `json:"restock_id" binding:"required"`
+	Items     []StockReservationItem `json:"items" binding:"required"`
+}
@@ -2,16 +2,35 @@ package routes
 
 import (
 	"product/cmd/product/handler"
+	"product/config"
 	"product/middleware"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func SetupRouter(router *gin.Engine, productHandler handler.ProductHandler) {
+func SetupRouter(router *gin.Engine, productHandler handler.ProductHandler, rdb *redis.Client, idempotencyCfg config.IdempotencyConfig, jwtSecret string) {
+	idempotency := middleware.Idempotency(rdb, idempotencyCfg.TTL, idempotencyCfg.LockTTL)
+
 	router.Use(middleware.RequestLogger())
-	router.POST("/v1/product_category", productHandler.ProductCategoryManagement)
-	router.POST("/v1/product", productHandler.ProductManagement)
+	router.POST("/v1/product_category", idempotency, productHandler.ProductCategoryManagement)
+	router.POST("/v1/product", idempotency, productHandler.ProductManagement)
 	router.GET("/v1/product/:id", productHandler.GetProduct)
+	router.POST("/v1/product/batch", productHandler.GetProductsBatch)
 	router.GET("/v1/product_category/:id", productHandler.GetProductCategory)
 	router.GET("/v1/product/search", productHandler.SearchProduct)
+	router.GET("/v1/exchange_rate", productHandler.GetExchangeRates)
+
+	// exchange rates decide what every order is charged and restocks what can be sold, only admins
+	// set them
+	admin := router.Group("/v1", middleware.AuthMiddleware(jwtSecret), middleware.AdminOnly())
+	admin.POST("/exchange_rate", productHandler.SetExchangeRate)
+	admin.POST("/product/stock/restock", productHandler.RestockItems)
+
+	// reservations hold and give back stock for the orders being placed, only the order service
+	// makes them
+	stock := router.Group("/v1/product/stock", middleware.AuthMiddleware(jwtSecret), middleware.ServiceOnly())
+	stock.POST("/reserve", productHandler.ReserveStock)
+	stock.POST("/commit", productHandler.CommitStock)
+	stock.POST("/release", productHandler.ReleaseStock)
 }
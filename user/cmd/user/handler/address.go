@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	constant "user/infrastructure/constans"
+	"user/infrastructure/log"
+	"user/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *UserHandler) GetAddresses(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.UserUseCase.GetAddresses(c.Request.Context(), userID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+		}).Errorf("h.UserUseCase.GetAddresses got error: %v", err)
+		writeAddressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"addresses": addresses,
+	})
+}
+
+func (h *UserHandler) GetAddress(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+
+	address, err := h.UserUseCase.GetAddress(c.Request.Context(), userID, addressID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":    userID,
+			"addressID": addressID,
+		}).Errorf("h.UserUseCase.GetAddress got error: %v", err)
+		writeAddressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"address": address,
+	})
+}
+
+func (h *UserHandler) CreateAddress(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+	var param models.AddressParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	address, err := h.UserUseCase.CreateAddress(c.Request.Context(), userID, param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+		}).Errorf("h.UserUseCase.CreateAddress got error: %v", err)
+		writeAddressError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"address": address,
+	})
+}
+
+func (h *UserHandler) UpdateAddress(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+	var param models.AddressParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	address, err := h.UserUseCase.UpdateAddress(c.Request.Context(), userID, addressID, param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":    userID,
+			"addressID": addressID,
+		}).Errorf("h.UserUseCase.UpdateAddress got error: %v", err)
+		writeAddressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"address": address,
+	})
+}
+
+func (h *UserHandler) DeleteAddress(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := h.UserUseCase.DeleteAddress(c.Request.Context(), userID, addressID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":    userID,
+			"addressID": addressID,
+		}).Errorf("h.UserUseCase.DeleteAddress got error: %v", err)
+		writeAddressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Address deleted",
+	})
+}
+
+func (h *UserHandler) SetDefaultAddress(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+
+	address, err := h.UserUseCase.SetDefaultAddress(c.Request.Context(), userID, addressID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":    userID,
+			"addressID": addressID,
+		}).Errorf("h.UserUseCase.SetDefaultAddress got error: %v", err)
+		writeAddressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"address": address,
+	})
+}
+
+func authUserID(c *gin.Context) (int64, bool) {
+	userIDStr, isExists := c.Get("user_id")
+	if !isExists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+	userID, ok := userIDStr.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid User id"})
+		return 0, false
+	}
+	return int64(userID), true
+}
+
+func addressIDParam(c *gin.Context) (int64, bool) {
+	addressID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address id"})
+		return 0, false
+	}
+	return addressID, true
+}
+
+func writeAddressError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, constant.ErrAddressNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, constant.ErrInvalidAddress):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, constant.ErrAddressLimitReached):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
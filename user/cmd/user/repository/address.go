@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	constant "user/infrastructure/constans"
+	"user/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FindAddressesByUserID returns the address book of the user, the default address first.
+func (r *UserRepository) FindAddressesByUserID(ctx context.Context, userID int64) ([]models.Address, error) {
+	var addresses []models.Address
+	err := r.Database.WithContext(ctx).Where("user_id = ?", userID).Order("is_default DESC, id").Find(&addresses).Error
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (r *UserRepository) FindAddressByID(ctx context.Context, userID int64, addressID int64) (*models.Address, error) {
+	var address models.Address
+	err := r.Database.WithContext(ctx).Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+// LockUserByIDTx locks the user row until the transaction ends. Address book changes take it
+// first, so concurrent changes can't leave a user with more or less than one default address,
+// even while the book is still empty.
+func (r *UserRepository) LockUserByIDTx(ctx context.Context, tx *gorm.DB, userID int64) error {
+	var user models.User
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return constant.ErrAddressNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepository) FindAddressesByUserIDTx(ctx context.Context, tx *gorm.DB, userID int64) ([]models.Address, error) {
+	var addresses []models.Address
+	err := tx.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&addresses).Error
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (r *UserRepository) InsertAddressTx(ctx context.Context, tx *gorm.DB, address *models.Address) error {
+	return tx.WithContext(ctx).Create(address).Error
+}
+
+func (r *UserRepository) UpdateAddressTx(ctx context.Context, tx *gorm.DB, address *models.Address) error {
+	return tx.WithContext(ctx).Save(address).Error
+}
+
+func (r *UserRepository) DeleteAddressTx(ctx context.Context, tx *gorm.DB, address *models.Address) error {
+	return tx.WithContext(ctx).Delete(address).Error
+}
+
+// ClearDefaultAddressTx unsets the default address of the user.
+func (r *UserRepository) ClearDefaultAddressTx(ctx context.Context, tx *gorm.DB, userID int64) error {
+	return tx.WithContext(ctx).Model(&models.Address{}).Where("user_id = ? AND is_default = true", userID).Update("is_default", false).Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -16,3 +18,8 @@ func NewUserRepository(redis *redis.Client, db *gorm.DB) *UserRepository {
 		Database: db,
 	}
 }
+
+// WithTransaction runs fn in a transaction, committed when fn returns nil and rolled back otherwise.
+func (r *UserRepository) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.Database.WithContext(ctx).Transaction(fn)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	constant "user/infrastructure/constans"
+	"user/models"
+
+	"gorm.io/gorm"
+)
+
+func (svc *UserService) GetAddresses(ctx context.Context, userID int64) ([]models.Address, error) {
+	addresses, err := svc.UserRepo.FindAddressesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (svc *UserService) GetAddress(ctx context.Context, userID int64, addressID int64) (*models.Address, error) {
+	address, err := svc.UserRepo.FindAddressByID(ctx, userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// CreateAddress adds the address to the user's address book, up to maxAddresses. The first address
+// of a user becomes the default one.
+func (svc *UserService) CreateAddress(ctx context.Context, address *models.Address, maxAddresses int) error {
+	return svc.UserRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		addresses, err := svc.lockAddressesTx(ctx, tx, address.UserID)
+		if err != nil {
+			return err
+		}
+		if len(addresses) >= maxAddresses {
+			return fmt.Errorf("%w: at most %d addresses can be saved", constant.ErrAddressLimitReached, maxAddresses)
+		}
+		if len(addresses) == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			err = svc.UserRepo.ClearDefaultAddressTx(ctx, tx, address.UserID)
+			if err != nil {
+				return err
+			}
+		}
+		return svc.UserRepo.InsertAddressTx(ctx, tx, address)
+	})
+}
+
+// UpdateAddress replaces an address of the user. The default address stays the default one until
+// another address is made the default.
+func (svc *UserService) UpdateAddress(ctx context.Context, address *models.Address) error {
+	return svc.UserRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		addresses, err := svc.lockAddressesTx(ctx, tx, address.UserID)
+		if err != nil {
+			return err
+		}
+		current, err := findAddress(addresses, address.ID)
+		if err != nil {
+			return err
+		}
+		address.CreateTime = current.CreateTime
+		if current.IsDefault {
+			address.IsDefault = true
+		} else if address.IsDefault {
+			err = svc.UserRepo.ClearDefaultAddressTx(ctx, tx, address.UserID)
+			if err != nil {
+				return err
+			}
+		}
+		return svc.UserRepo.UpdateAddressTx(ctx, tx, address)
+	})
+}
+
+// DeleteAddress removes an address of the user. When it was the default, the oldest remaining
+// address becomes the default.
+func (svc *UserService) DeleteAddress(ctx context.Context, userID int64, addressID int64) error {
+	return svc.UserRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		addresses, err := svc.lockAddressesTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		address, err := findAddress(addresses, addressID)
+		if err != nil {
+			return err
+		}
+		err = svc.UserRepo.DeleteAddressTx(ctx, tx, &address)
+		if err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+		for _, remaining := range addresses {
+			if remaining.ID != addressID {
+				remaining.IsDefault = true
+				return svc.UserRepo.UpdateAddressTx(ctx, tx, &remaining)
+			}
+		}
+		return nil
+	})
+}
+
+func (svc *UserService) SetDefaultAddress(ctx context.Context, userID int64, addressID int64) (*models.Address, error) {
+	var address models.Address
+	err := svc.UserRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		addresses, err := svc.lockAddressesTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		address, err = findAddress(addresses, addressID)
+		if err != nil {
+			return err
+		}
+		if address.IsDefault {
+			return nil
+		}
+		err = svc.UserRepo.ClearDefaultAddressTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		address.IsDefault = true
+		return svc.UserRepo.UpdateAddressTx(ctx, tx, &address)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// lockAddressesTx locks the user's address book and returns it, oldest address first.
+func (svc *UserService) lockAddressesTx(ctx context.Context, tx *gorm.DB, userID int64) ([]models.Address, error) {
+	err := svc.UserRepo.LockUserByIDTx(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UserRepo.FindAddressesByUserIDTx(ctx, tx, userID)
+}
+
+func findAddress(addresses []models.Address, addressID int64) (models.Address, error) {
+	for _, address := range addresses {
+		if address.ID == addressID {
+			return address, nil
+		}
+	}
+	return models.Address{}, constant.ErrAddressNotFound
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	constant "user/infrastructure/constans"
+	"user/models"
+)
+
+const maxAddresses = 20
+
+var (
+	countryPattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+	regionPattern     = regexp.MustCompile(`^[A-Z0-9]{1,3}$`)
+	postalCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,9}$`)
+	phonePattern      = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,19}$`)
+)
+
+func (uc *UserUseCase) GetAddresses(ctx context.Context, userID int64) ([]models.Address, error) {
+	return uc.UserService.GetAddresses(ctx, userID)
+}
+
+func (uc *UserUseCase) GetAddress(ctx context.Context, userID int64, addressID int64) (*models.Address, error) {
+	return uc.UserService.GetAddress(ctx, userID, addressID)
+}
+
+func (uc *UserUseCase) CreateAddress(ctx context.Context, userID int64, param models.AddressParameter) (*models.Address, error) {
+	address, err := newAddress(param)
+	if err != nil {
+		return nil, err
+	}
+	address.UserID = userID
+	err = uc.UserService.CreateAddress(ctx, &address, maxAddresses)
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (uc *UserUseCase) UpdateAddress(ctx context.Context, userID int64, addressID int64, param models.AddressParameter) (*models.Address, error) {
+	address, err := newAddress(param)
+	if err != nil {
+		return nil, err
+	}
+	address.ID = addressID
+	address.UserID = userID
+	err = uc.UserService.UpdateAddress(ctx, &address)
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (uc *UserUseCase) DeleteAddress(ctx context.Context, userID int64, addressID int64) error {
+	return uc.UserService.DeleteAddress(ctx, userID, addressID)
+}
+
+func (uc *UserUseCase) SetDefaultAddress(ctx context.Context, userID int64, addressID int64) (*models.Address, error) {
+	return uc.UserService.SetDefaultAddress(ctx, userID, addressID)
+}
+
+// newAddress normalizes the parameter into an address and validates the fields the binding tags
+// can't: the country and region codes, the postal code and the phone number.
+func newAddress(param models.AddressParameter) (models.Address, error) {
+	address := models.Address{
+		Label:      strings.TrimSpace(param.Label),
+		Recipient:  strings.TrimSpace(param.Recipient),
+		Line1:      strings.TrimSpace(param.Line1),
+		Line2:      strings.TrimSpace(param.Line2),
+		City:       strings.TrimSpace(param.City),
+		Region:     strings.ToUpper(strings.TrimSpace(param.Region)),
+		PostalCode: strings.ToUpper(strings.TrimSpace(param.PostalCode)),
+		Country:    strings.ToUpper(strings.TrimSpace(param.Country)),
+		Phone:      strings.TrimSpace(param.Phone),
+		IsDefault:  param.IsDefault,
+	}
+	switch {
+	case address.Recipient == "" || address.Line1 == "" || address.City == "":
+		return models.Address{}, fmt.Errorf("%w: recipient, line1 and city are required", constant.ErrInvalidAddress)
+	case !countryPattern.MatchString(address.Country):
+		return models.Address{}, fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", constant.ErrInvalidAddress)
+	case address.Region != "" && !regionPattern.MatchString(address.Region):
+		return models.Address{}, fmt.Errorf("%w: region must be an ISO 3166-2 subdivision code", constant.ErrInvalidAddress)
+	case address.PostalCode != "" && !postalCodePattern.MatchString(address.PostalCode):
+		return models.Address{}, fmt.Errorf("%w: invalid postal code", constant.ErrInvalidAddress)
+	case !phonePattern.MatchString(address.Phone):
+		return models.Address{}, fmt.Errorf("%w: invalid phone number", constant.ErrInvalidAddress)
+	}
+	return address, nil
+}
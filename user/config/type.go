@@ -1,10 +1,13 @@
 package config
 
+import "time"
+
 type Config struct {
-	App      AppConfig      `yaml:"app" validate:"required"`
-	Database DatabaseConfig `yaml:"database" validate:"required"`
-	Redis    RedisConfig    `yaml:"redis" validate:"required"`
-	Secret   SecretConfig   `yaml:"secret" validate:"required"`
+	App         AppConfig         `yaml:"app" validate:"required"`
+	Database    DatabaseConfig    `yaml:"database" validate:"required"`
+	Redis       RedisConfig       `yaml:"redis" validate:"required"`
+	Secret      SecretConfig      `yaml:"secret" validate:"required"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
 }
 
 type AppConfig struct {
@@ -29,3 +32,8 @@ type RedisConfig struct {
 type SecretConfig struct {
 	JWTSecret string `yaml:"jwt_secret" validate:"required"`
 }
+
+type IdempotencyConfig struct {
+	TTL     time.Duration `yaml:"ttl"`
+	LockTTL time.Duration `yaml:"lock_ttl" mapstructure:"lock_ttl"`
+}
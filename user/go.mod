@@ -13,6 +13,7 @@ require (
 	golang.org/x/net v0.25.0
 	gorm.io/driver/postgres v1.6.0
 	gorm.io/gorm v1.31.0
+	idempotency v0.0.0
 )
 
 require (
@@ -59,3 +60,5 @@ require (
 	google.golang.org/protobuf v1.34.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace idempotency => ../idempotency
@@ -0,0 +1,9 @@
+package constant
+
+import "errors"
+
+var (
+	ErrAddressNotFound     = errors.New("address not found")
+	ErrInvalidAddress      = errors.New("invalid address")
+	ErrAddressLimitReached = errors.New("address book is full")
+)
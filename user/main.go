@@ -27,7 +27,7 @@ func main() {
 	port := cfg.App.Port
 	router := gin.Default()
 
-	routes.SetupRoutes(router, *UserHandler, cfg.Secret.JWTSecret)
+	routes.SetupRoutes(router, *UserHandler, cfg.Secret.JWTSecret, redis, cfg.Idempotency)
 
 	router.Run(":" + port)
 
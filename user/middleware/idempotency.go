@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"idempotency"
+	"time"
+	"user/infrastructure/log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// Idempotency makes a mutating endpoint safe to retry with an Idempotency-Key header, see
+// idempotency.Middleware.
+func Idempotency(rdb *redis.Client, ttl time.Duration, lockTTL time.Duration) gin.HandlerFunc {
+	return idempotency.Middleware(rdb, idempotency.Config{
+		TTL:      ttl,
+		LockTTL:  lockTTL,
+		ErrorKey: "error",
+		Logger:   log.Logger,
+	})
+}
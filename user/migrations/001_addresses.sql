@@ -0,0 +1,20 @@
+-- address book of every user, at most one default address per user
+create table addresses (
+    id bigserial primary key,
+    user_id bigint not null references users(id),
+    label varchar(50) not null default '',
+    recipient varchar(100) not null,
+    line1 varchar(255) not null,
+    line2 varchar(255) not null default '',
+    city varchar(100) not null,
+    region varchar(3) not null default '',
+    postal_code varchar(20) not null default '',
+    country char(2) not null,
+    phone varchar(30) not null,
+    is_default boolean not null default false,
+    create_time timestamp default current_timestamp,
+    update_time timestamp default current_timestamp
+);
+
+create index idx_addresses_user on addresses (user_id);
+create unique index uq_addresses_user_default on addresses (user_id) where is_default;
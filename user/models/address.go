@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Address is an entry of a user's address book. Country is the ISO 3166-1 alpha-2 code and
+// Region the ISO 3166-2 subdivision code without the country prefix, e.g. "JK" in "ID-JK".
+type Address struct {
+	ID         int64     `gorm:"primaryKey" json:"id"`
+	UserID     int64     `gorm:"not null" json:"user_id"`
+	Label      string    `json:"label"`
+	Recipient  string    `json:"recipient"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2"`
+	City       string    `json:"city"`
+	Region     string    `json:"region"`
+	PostalCode string    `json:"postal_code"`
+	Country    string    `json:"country"`
+	Phone      string    `json:"phone"`
+	IsDefault  bool      `json:"is_default"`
+	CreateTime time.Time `gorm:"autoCreateTime" json:"create_time"`
+	UpdateTime time.Time `gorm:"autoUpdateTime" json:"update_time"`
+}
+
+type AddressParameter struct {
+	Label      string `json:"label" binding:"max=50"`
+	Recipient  string `json:"recipient" binding:"required,max=100"`
+	Line1      string `json:"line1" binding:"required,max=255"`
+	Line2      string `json:"line2" binding:"max=255"`
+	City       string `json:"city" binding:"required,max=100"`
+	Region     string `json:"region" binding:"max=3"`
+	PostalCode string `json:"postal_code" binding:"max=20"`
+	Country    string `json:"country" binding:"required,len=2"`
+	Phone      string `json:"phone" binding:"required,max=30"`
+	IsDefault  bool   `json:"is_default"`
+}
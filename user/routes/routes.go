@@ -2,20 +2,30 @@ package routes
 
 import (
 	"user/cmd/user/handler"
+	"user/config"
 	"user/middleware"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func SetupRoutes(router *gin.Engine, userHandler handler.UserHandler, jwtSecret string) {
+func SetupRoutes(router *gin.Engine, userHandler handler.UserHandler, jwtSecret string, rdb *redis.Client, idempotencyCfg config.IdempotencyConfig) {
+	idempotency := middleware.Idempotency(rdb, idempotencyCfg.TTL, idempotencyCfg.LockTTL)
+
 	//Public API
 	router.Use(middleware.RequestLogger())
 	router.GET("/ping", userHandler.Ping)
-	router.POST("/v1/register", userHandler.RegisterRoutes)
+	router.POST("/v1/register", idempotency, userHandler.RegisterRoutes)
 	router.POST("/v1/login", userHandler.LoginRoutes)
 	// Private API
 	authMiddleware := middleware.AuthMiddleware(jwtSecret)
 	private := router.Group("/api")
 	private.Use(authMiddleware)
 	private.GET("/v1/user_info", userHandler.GetUserInfo)
+	private.GET("/v1/address", userHandler.GetAddresses)
+	private.POST("/v1/address", userHandler.CreateAddress)
+	private.GET("/v1/address/:id", userHandler.GetAddress)
+	private.PUT("/v1/address/:id", userHandler.UpdateAddress)
+	private.DELETE("/v1/address/:id", userHandler.DeleteAddress)
+	private.PUT("/v1/address/:id/default", userHandler.SetDefaultAddress)
 }